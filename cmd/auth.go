@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+	"github.com/TheAlonso95/ai-dev-agent/internal/credentials"
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+)
+
+var (
+	authService   string
+	authWithToken bool
+
+	// ghToken authenticates GitHub calls, as the configured App or with the token. It is
+	// set by requireCredentials.
+	ghToken github.TokenSource
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Store and check GitHub and OpenAI credentials",
+	Long: `Secrets can be kept out of .env files by setting credential_store in the config:
+
+  command  run a helper that prints the secret (default for github: gh auth token)
+  git      use git's credential helpers (OS keychain, libsecret, manager, ...)
+  file     an encrypted file unlocked with a passphrase ($AIAGENT_PASSPHRASE or prompt)
+
+The store is consulted for secrets not set in the config file or the environment.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Verify a token and save it in the credential store",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if authService != credentials.GitHub && authService != credentials.OpenAI {
+			fatalf("Unknown service %q (want github or openai)", authService)
+		}
+		credStore, err := openCredentialStore()
+		if err != nil {
+			fatal(err)
+		}
+		if credStore == nil {
+			fatal("No credential store configured; set credential_store to git or file in the config")
+		}
+
+		var token string
+		if authWithToken {
+			var data []byte
+			data, err = io.ReadAll(stdin)
+			token = strings.TrimSpace(string(data))
+		} else {
+			token, err = askSecret(fmt.Sprintf("Paste your %s token", serviceLabel(authService)))
+		}
+		if err != nil {
+			fatal(err)
+		}
+		if token == "" {
+			fatal("No token given")
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), apiTimeout)
+		defer cancel()
+		switch authService {
+		case credentials.GitHub:
+			info, err := github.GetTokenInfo(ctx, token)
+			if err != nil {
+				fatalf("GitHub rejected the token: %v", err)
+			}
+			if missing := info.MissingScopes(github.RequiredScopes); len(missing) > 0 {
+				fatalf("Token is missing required scopes: %s", strings.Join(missing, ", "))
+			}
+			fmt.Printf("✅ Token belongs to %s\n", info.Login)
+		case credentials.OpenAI:
+			if err := openai.CheckKey(ctx, token); err != nil {
+				fatalf("%v", err)
+			}
+			fmt.Println("✅ OpenAI accepted the key")
+		}
+
+		saveCtx, cancel := context.WithTimeout(cmd.Context(), credentialTimeout)
+		defer cancel()
+		if err := credStore.Set(saveCtx, authService, token); err != nil {
+			if errors.Is(err, credentials.ErrReadOnly) {
+				fatalf("The %s credential store is read-only; log in with the helper itself (e.g. gh auth login)", credStore.Backend())
+			}
+			fatalf("Failed to save the token: %v", err)
+		}
+		fmt.Printf("🔐 Saved %s credentials in the %s credential store\n", serviceLabel(authService), credStore.Backend())
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where credentials come from and check the GitHub token's scopes or App installation",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), apiTimeout)
+		defer cancel()
+		ok := true
+		result := &authOutcome{}
+		current.Result = result
+
+		if err := resolveCredentials(cmd.Context()); err != nil {
+			fatal(err)
+		}
+		token, tokenErr := githubTokenSource()
+		if app, isApp := token.(*github.AppTokenSource); isApp {
+			result.GitHub.Source = cfgSources["github_app"]
+			if _, err := app.Token(ctx); err != nil {
+				fmt.Printf("❌ GitHub: App %d could not authenticate: %v\n", app.AppID, err)
+				result.GitHub.Error = err.Error()
+				ok = false
+			} else {
+				result.GitHub.OK = true
+				fmt.Printf("✅ GitHub: authenticated as App %d, installation %d (token from %s, expires %s)\n",
+					app.AppID, app.InstallationID, cfgSources["github_app"], app.Expiry().Local().Format(time.Kitchen))
+			}
+		} else if tokenErr != nil {
+			fmt.Printf("❌ GitHub: %v\n", tokenErr)
+			result.GitHub.Source, result.GitHub.Error = cfgSources["github_app"], tokenErr.Error()
+			ok = false
+		} else if cfg.GitHubToken == "" {
+			fmt.Println("❌ GitHub: no token configured")
+			result.GitHub.Error = "no token configured"
+			ok = false
+		} else if info, err := github.GetTokenInfo(ctx, cfg.GitHubToken); err != nil {
+			fmt.Printf("❌ GitHub: token from %s was rejected: %v\n", cfgSources[config.FieldGitHubToken], err)
+			result.GitHub.Source, result.GitHub.Error = cfgSources[config.FieldGitHubToken], err.Error()
+			ok = false
+		} else {
+			result.GitHub = credentialCheck{OK: true, Source: cfgSources[config.FieldGitHubToken], Login: info.Login, Scopes: info.Scopes}
+			fmt.Printf("✅ GitHub: logged in as %s (token from %s)\n", info.Login, cfgSources[config.FieldGitHubToken])
+			if info.Scopes == nil {
+				fmt.Println("   Fine-grained token: permissions can't be checked up front")
+			} else {
+				fmt.Printf("   Scopes: %s\n", strings.Join(info.Scopes, ", "))
+			}
+			if missing := info.MissingScopes(github.RequiredScopes); len(missing) > 0 {
+				fmt.Printf("   ❌ Missing required scopes: %s\n", strings.Join(missing, ", "))
+				result.GitHub.OK, result.GitHub.Error = false, "missing required scopes: "+strings.Join(missing, ", ")
+				ok = false
+			}
+			if cfg.GitHubUsername != "" && !strings.EqualFold(cfg.GitHubUsername, info.Login) {
+				warn("github_username is %s but the token belongs to %s", cfg.GitHubUsername, info.Login)
+			}
+		}
+
+		if cfg.OpenAIAPIKey == "" {
+			fmt.Println("❌ OpenAI: no API key configured")
+			result.OpenAI.Error = "no API key configured"
+			ok = false
+		} else if err := openai.CheckKey(ctx, cfg.OpenAIAPIKey); err != nil {
+			fmt.Printf("❌ OpenAI: key from %s was rejected: %v\n", cfgSources[config.FieldOpenAIAPIKey], err)
+			result.OpenAI.Source, result.OpenAI.Error = cfgSources[config.FieldOpenAIAPIKey], err.Error()
+			ok = false
+		} else {
+			fmt.Printf("✅ OpenAI: key from %s is valid\n", cfgSources[config.FieldOpenAIAPIKey])
+			result.OpenAI = credentialCheck{OK: true, Source: cfgSources[config.FieldOpenAIAPIKey]}
+		}
+
+		if !ok {
+			current.Status = statusFailed
+		}
+	},
+}
+
+// authOutcome is the result of auth status in the JSON report
+type authOutcome struct {
+	GitHub credentialCheck `json:"github"`
+	OpenAI credentialCheck `json:"openai"`
+}
+
+// credentialCheck is whether one service accepted its credentials
+type credentialCheck struct {
+	OK     bool     `json:"ok"`
+	Source string   `json:"source,omitempty"`
+	Login  string   `json:"login,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// requireCredentials fills the secrets the configuration left unset from the credential
+// store and checks that fields have a value. When fields include the GitHub token it
+// also sets ghToken. Only commands that call GitHub or OpenAI need it, so a broken
+// store or App key never gets in the way of the others.
+func requireCredentials(ctx context.Context, fields ...string) error {
+	if err := resolveCredentials(ctx); err != nil {
+		return err
+	}
+	if err := cfg.Require(fields...); err != nil {
+		return err
+	}
+	if !slices.Contains(fields, config.FieldGitHubToken) {
+		return nil
+	}
+	var err error
+	ghToken, err = githubTokenSource()
+	return err
+}
+
+// resolveCredentials fills the secrets the configuration left unset from the credential
+// store, if one is configured
+func resolveCredentials(ctx context.Context) error {
+	store, err := openCredentialStore()
+	if err != nil || store == nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, credentialTimeout)
+	defer cancel()
+	return cfg.ResolveCredentials(ctx, store, cfgSources)
+}
+
+// openCredentialStore builds the configured credential store, or returns nil when none
+// is configured
+func openCredentialStore() (credentials.Store, error) {
+	if cfg.CredentialStore == "" {
+		return nil, nil
+	}
+	path, err := cfg.CredentialFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.New(cfg.CredentialStore, credentials.Options{
+		Commands:   cfg.CredentialCommands,
+		Username:   cfg.GitHubUsername,
+		Path:       path,
+		Passphrase: passphrase,
+	})
+}
+
+// githubTokenSource returns the configured GitHub App installation, or the token
+func githubTokenSource() (github.TokenSource, error) {
+	app := cfg.GitHubApp
+	if app == nil {
+		return github.StaticToken(cfg.GitHubToken), nil
+	}
+	key, err := os.ReadFile(app.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	return github.NewAppTokenSource(app.AppID, app.InstallationID, cfg.GitHubUsername, key)
+}
+
+// passphrase unlocks the encrypted credentials file from $AIAGENT_PASSPHRASE or, on a
+// terminal, a prompt. A new file's passphrase is asked for twice, since a typo would
+// lock the secrets away.
+func passphrase(create bool) (string, error) {
+	if p := os.Getenv(config.EnvPassphrase); p != "" {
+		return p, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("set $%s to unlock the credentials file", config.EnvPassphrase)
+	}
+	if !create {
+		return askSecret("Credentials passphrase")
+	}
+
+	p, err := askSecret("New credentials passphrase")
+	if err != nil {
+		return "", err
+	}
+	again, err := askSecret("Repeat the passphrase")
+	if err != nil {
+		return "", err
+	}
+	if p != again {
+		return "", errors.New("passphrases don't match")
+	}
+	return p, nil
+}
+
+func serviceLabel(service string) string {
+	if service == credentials.OpenAI {
+		return "OpenAI"
+	}
+	return "GitHub"
+}
+
+func init() {
+	authLoginCmd.Flags().StringVar(&authService, "service", credentials.GitHub, "Credential to save: github or openai")
+	authLoginCmd.Flags().BoolVar(&authWithToken, "with-token", false, "Read the token from standard input")
+	authCmd.AddCommand(authLoginCmd, authStatusCmd)
+	rootCmd.AddCommand(authCmd)
+}
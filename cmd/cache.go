@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/cache"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+)
+
+var (
+	noCache  bool
+	pruneAll bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "List, inspect and prune cached LLM responses",
+	Long: `LLM responses are cached on disk, keyed by a hash of the model and messages, so
+re-running init or plan with the same inputs reuses them for free. Entries expire
+after cache_ttl (7 days by default); --no-cache bypasses the cache for a run.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := mustOpenCache()
+		entries, bad, err := c.List()
+		if err != nil {
+			fatal(err)
+		}
+		for _, b := range bad {
+			warn("Skipped unreadable cache entry (remove it with 'cache prune'): %v", b.Err)
+		}
+		result := cacheOutcome{Dir: c.Dir, Entries: []cachedEntry{}}
+		for _, e := range entries {
+			result.Entries = append(result.Entries, cachedEntry{
+				Key: e.Key, Model: e.Model, Summary: e.Summary, CreatedAt: e.CreatedAt, Expired: c.Expired(e),
+			})
+		}
+		current.Result = result
+		if len(entries) == 0 {
+			fmt.Println("No cached responses")
+			return
+		}
+		for _, e := range entries {
+			age := time.Since(e.CreatedAt).Round(time.Minute)
+			status := ""
+			if c.Expired(e) {
+				status = " (expired)"
+			}
+			fmt.Printf("%-12.12s  %-20s %8s ago%s  %s\n", e.Key, e.Model, age, status, e.Summary)
+		}
+		fmt.Println("\nCache directory:", c.Dir)
+	},
+}
+
+var cacheShowCmd = &cobra.Command{
+	Use:   "show <key>",
+	Short: "Print a cached response by key or key prefix",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		e, err := mustOpenCache().Lookup(args[0])
+		if err != nil {
+			fatal(err)
+		}
+		current.Result = e
+		fmt.Printf("Key:     %s\nModel:   %s\nCreated: %s\nPrompt:  %s\n\n%s\n",
+			e.Key, e.Model, e.CreatedAt.Local().Format(time.DateTime), e.Summary, e.Content)
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete expired and unreadable cached responses, or all of them with --all",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := mustOpenCache().Prune(pruneAll)
+		if err != nil {
+			fatal(err)
+		}
+		current.Result = map[string]int{"removed": removed}
+		fmt.Printf("🧹 Removed %d cached response(s)\n", removed)
+	},
+}
+
+// cacheOutcome is the result of cache in the JSON report
+type cacheOutcome struct {
+	Dir     string        `json:"dir"`
+	Entries []cachedEntry `json:"entries"`
+}
+
+// cachedEntry is a cache entry without its content
+type cachedEntry struct {
+	Key       string    `json:"key"`
+	Model     string    `json:"model"`
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+	Expired   bool      `json:"expired"`
+}
+
+// openCache returns the response cache with the configured TTL
+func openCache() (*cache.Cache, error) {
+	ttl, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache_ttl %q: %w", cfg.CacheTTL, err)
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return &cache.Cache{Dir: dir, TTL: ttl}, nil
+}
+
+// mustOpenCache is openCache for the cache commands
+func mustOpenCache() *cache.Cache {
+	c, err := openCache()
+	if err != nil {
+		fatal(err)
+	}
+	return c
+}
+
+// startCache serves repeated LLM calls from the cache unless --no-cache is set
+func startCache() error {
+	if noCache {
+		openai.SetCache(nil)
+		return nil
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	openai.SetCache(c)
+	return nil
+}
+
+func init() {
+	cachePruneCmd.Flags().BoolVar(&pruneAll, "all", false, "Delete every cached response, not only expired ones")
+	cacheCmd.AddCommand(cacheShowCmd, cachePruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Send every LLM request instead of reusing cached responses")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/plan"
+)
+
+var (
+	clarifyIdea bool
+	answersPath string
+)
+
+// clarify has the planner ask its clarifying questions about the idea and collects the
+// answers into a project brief. Answers come from --answers, matched by topic, and
+// then from the terminal when interactive. Without either there is no one to answer,
+// so the phase is skipped and the brief is nil.
+func clarify(ctx context.Context, idea, stack, openaiKey string, interactive bool) (*plan.Brief, error) {
+	if !clarifyIdea || (!interactive && answersPath == "") {
+		return nil, nil
+	}
+
+	fileAnswers := map[string]string{}
+	if answersPath != "" {
+		var err error
+		if fileAnswers, err = plan.LoadAnswers(answersPath); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Println("🧠 Working out what to ask about the idea...")
+	llmCtx, cancel := context.WithTimeout(ctx, llmTimeout)
+	questions, err := openai.AskClarifyingQuestions(llmCtx, idea, stack, openaiKey)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	brief := &plan.Brief{}
+	asked := false
+	for _, q := range questions {
+		answer, ok := fileAnswers[q.Topic]
+		delete(fileAnswers, q.Topic)
+		if !ok && interactive {
+			if !asked {
+				fmt.Println("\n❓ A few questions before planning (leave empty to let the planner decide)")
+				asked = true
+			}
+			answer = ask(q.Question, "")
+		}
+		if answer != "" {
+			brief.Answers = append(brief.Answers, plan.Answer{Topic: q.Topic, Question: q.Question, Answer: answer})
+		}
+	}
+
+	// Answers the planner didn't ask about are still part of the brief
+	topics := make([]string, 0, len(fileAnswers))
+	for topic := range fileAnswers {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	for _, topic := range topics {
+		brief.Answers = append(brief.Answers, plan.Answer{Topic: topic, Answer: fileAnswers[topic]})
+	}
+
+	if len(brief.Answers) == 0 {
+		return nil, nil
+	}
+	return brief, nil
+}
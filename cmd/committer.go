@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+// Values accepted by --git-backend
+const (
+	backendAPI = "api"
+	backendGit = "git"
+)
+
+var gitBackend string
+
+// newCommitter returns the Committer selected with --git-backend
+func newCommitter(owner, repo string, token github.TokenSource) (github.Committer, error) {
+	switch gitBackend {
+	case backendAPI:
+		return &github.APICommitter{Owner: owner, Repo: repo, Token: token}, nil
+	case backendGit:
+		return github.NewGitCommitter(owner, repo, token), nil
+	}
+	return nil, fmt.Errorf("invalid --git-backend value %q (want %s or %s)", gitBackend, backendAPI, backendGit)
+}
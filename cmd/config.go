@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+)
+
+var (
+	cfgFile    string
+	cfgProfile string
+
+	// cfg is the effective configuration, loaded before any command runs
+	cfg        *config.Config
+	cfgSources config.Sources
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and validate the effective configuration",
+	Long: `Configuration is layered: built-in defaults, then ~/.config/aiagent/config.yaml
+(top-level values, then the selected profile), then environment variables (and a
+.env file in the working directory), then command-line flags.
+
+  github_username: me
+  default_profile: personal
+  profiles:
+    personal:
+      github_token: ghp_xxx
+      openai_api_key: sk-xxx
+    work:
+      github_username: me-at-work
+      github_token: ghp_yyy
+      git_backend: git
+      actions_allow: ["actions/*", "my-org/*"]`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration with secrets redacted",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, err := yaml.Marshal(cfg.Redacted())
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Print(string(out))
+
+		// The report uses the configuration file's field names
+		result := configOutcome{Sources: cfgSources}
+		if err := yaml.Unmarshal(out, &result.Config); err != nil {
+			fatal(err)
+		}
+		current.Result = result
+
+		fields := make([]string, 0, len(cfgSources))
+		for field := range cfgSources {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		fmt.Println("\n# Sources")
+		for _, field := range fields {
+			fmt.Printf("#   %-20s %s\n", field, cfgSources[field])
+		}
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the effective configuration for missing or invalid values",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := resolveCredentials(cmd.Context()); err != nil {
+			fatal(err)
+		}
+		if err := cfg.Validate(); err != nil {
+			fatalf("Invalid configuration:\n%v", err)
+		}
+		current.Result = map[string]bool{"valid": true}
+		fmt.Println("✅ Configuration is valid")
+	},
+}
+
+// configOutcome is the result of config show in the JSON report
+type configOutcome struct {
+	Config  map[string]any    `json:"config"`
+	Sources map[string]string `json:"sources"`
+}
+
+// loadConfig loads the configuration and layers explicitly set flags on top of it.
+// Flags the user didn't set take their value from the configuration instead.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if err := startOutput(cmd); err != nil {
+		return err
+	}
+	if err := startLogging(cmd); err != nil {
+		return err
+	}
+
+	var err error
+	cfg, cfgSources, err = config.Load(cfgFile, cfgProfile)
+	if err != nil {
+		return err
+	}
+
+	layerFlag(cmd, "git-backend", "git_backend", &gitBackend, &cfg.GitBackend)
+	layerFlag(cmd, "actions-allow", "actions_allow", &actionsAllow, &cfg.ActionsAllow)
+	layerFlag(cmd, "license", "license", &licenseKey, &cfg.License)
+	layerFlag(cmd, "max-cost", "max_cost", &maxCost, &cfg.MaxCost)
+	startMeter()
+	if err := startCache(); err != nil {
+		return err
+	}
+
+	return setPromptOverrides()
+}
+
+// layerFlag reconciles a flag with its configuration field: a flag set on the command line
+// overrides the configuration, otherwise the flag takes the configured value
+func layerFlag[T any](cmd *cobra.Command, name, field string, flagValue, cfgValue *T) {
+	f := cmd.Flags().Lookup(name)
+	if f == nil {
+		return
+	}
+	if f.Changed {
+		*cfgValue = *flagValue
+		cfgSources[field] = "flag --" + name
+	} else {
+		*flagValue = *cfgValue
+	}
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd, configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+	"github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+// Duplicate policies accepted by --on-duplicate
+const (
+	duplicateSkip    = "skip"    // don't file the task
+	duplicateComment = "comment" // add the task as a comment on the existing issue
+	duplicateWarn    = "warn"    // file the task anyway and report the match
+)
+
+// duplicate pairs a planned task with the existing issue it matches
+type duplicate struct {
+	Task   tasks.Task
+	Issue  github.Issue
+	Score  float64
+	Action string
+}
+
+func validateDuplicatePolicy(policy string) error {
+	switch policy {
+	case duplicateSkip, duplicateComment, duplicateWarn:
+		return nil
+	}
+	return fmt.Errorf("invalid --on-duplicate value %q (want %s, %s or %s)",
+		policy, duplicateSkip, duplicateComment, duplicateWarn)
+}
+
+// findDuplicate returns the existing issue that best matches the task, if any
+// scores at or above tasks.DuplicateThreshold
+func findDuplicate(task tasks.Task, existing []github.Issue) (github.Issue, float64, bool) {
+	var best github.Issue
+	bestScore := 0.0
+	for _, issue := range existing {
+		score := tasks.Similarity(task.Title, task.Body, issue.Title, issue.Body)
+		if score > bestScore {
+			best, bestScore = issue, score
+		}
+	}
+	return best, bestScore, bestScore >= tasks.DuplicateThreshold
+}
+
+// filterDuplicates compares planned tasks against the repository's existing issues and
+// applies the duplicate policy. It returns the tasks that still need an issue along with
+// a record of every duplicate found. A comment that can't be posted is reported and the
+// task skipped.
+func filterDuplicates(ctx context.Context, owner, repo string, token github.TokenSource, policy string, planned []tasks.Task) ([]tasks.Task, []duplicate, error) {
+	listCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+	existing, err := github.ListIssues(listCtx, owner, repo, token)
+	cancel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list existing issues: %w", err)
+	}
+
+	var remaining []tasks.Task
+	var dups []duplicate
+	for _, task := range planned {
+		issue, score, ok := findDuplicate(task, existing)
+		if !ok {
+			remaining = append(remaining, task)
+			continue
+		}
+
+		dup := duplicate{Task: task, Issue: issue, Score: score, Action: policy}
+		switch policy {
+		case duplicateWarn:
+			remaining = append(remaining, task)
+		case duplicateComment:
+			comment := fmt.Sprintf("**Related planned task:** %s\n\n%s", task.Title, github.IssueBody(task))
+			commentCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+			err := github.CreateIssueComment(commentCtx, owner, repo, issue.Number, token, comment)
+			cancel()
+			// The task is still a duplicate, so it is skipped rather than filed
+			if err != nil {
+				fmt.Printf("❌ Failed to comment on issue #%d: %v\n", issue.Number, err)
+				partialFailure("failed to comment on issue #%d: %v", issue.Number, err)
+				dup.Action = duplicateSkip
+			}
+		}
+		dups = append(dups, dup)
+	}
+	return remaining, dups, nil
+}
+
+// duplicateOutcome is a duplicate task in the JSON report
+type duplicateOutcome struct {
+	Task   string  `json:"task"`
+	Issue  int     `json:"issue"`
+	Score  float64 `json:"score"`
+	Action string  `json:"action"`
+}
+
+func duplicateOutcomes(dups []duplicate) []duplicateOutcome {
+	var outcomes []duplicateOutcome
+	for _, d := range dups {
+		outcomes = append(outcomes, duplicateOutcome{Task: d.Task.Title, Issue: d.Issue.Number, Score: d.Score, Action: d.Action})
+	}
+	return outcomes
+}
+
+func printDuplicates(dups []duplicate) {
+	if len(dups) == 0 {
+		return
+	}
+	fmt.Printf("🔁 Found %d duplicate task(s):\n", len(dups))
+	for _, d := range dups {
+		var verb string
+		switch d.Action {
+		case duplicateSkip:
+			verb = "skipped"
+		case duplicateComment:
+			verb = "merged as comment into"
+		case duplicateWarn:
+			verb = "created anyway, matches"
+		}
+		fmt.Printf("   - %q %s #%d %q (%s, similarity %.2f)\n",
+			d.Task.Title, verb, d.Issue.Number, d.Issue.Title, d.Issue.State, d.Score)
+	}
+}
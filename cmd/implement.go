@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+)
+
+// Limits on how much of the repository is sent to the model as context
+const (
+	maxContextFiles    = 20
+	maxContextBytes    = 100_000
+	maxContextFileSize = 50_000
+)
+
+var (
+	implementRepo      string
+	implementBranch    string
+	implementDraft     bool
+	implementReviewers []string
+	implementLabels    []string
+)
+
+// skippedDirs and skippedExts mark files that are never useful as model context
+var (
+	skippedDirs = []string{".git/", "node_modules/", "vendor/", "dist/", "build/"}
+	skippedExts = map[string]bool{
+		".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".ico": true, ".svg": true,
+		".woff": true, ".woff2": true, ".ttf": true, ".zip": true, ".gz": true, ".pdf": true,
+		".exe": true, ".bin": true, ".lock": true, ".sum": true,
+	}
+)
+
+var implementCmd = &cobra.Command{
+	Use:   "implement [issue]",
+	Short: "Use AI to implement a GitHub issue and open a pull request",
+	Long: `This command reads an issue, asks AI to write the code for it, commits the result on a feature branch and opens a pull request.
+		Example:
+  		aiagent implement 12 --repo pomodoro-timer`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueNumber, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil {
+			fatalf("Invalid issue number %q", args[0])
+		}
+
+		if err := requireCredentials(cmd.Context(), config.FieldGitHubToken, config.FieldGitHubUsername, config.FieldOpenAIAPIKey); err != nil {
+			fatal(err)
+		}
+
+		token := ghToken
+		openaiKey := cfg.OpenAIAPIKey
+		owner := cfg.GitHubUsername
+		repo := implementRepo
+
+		ctx := cmd.Context()
+		opCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+		issue, err := github.FetchIssue(opCtx, owner, repo, issueNumber, token)
+		cancel()
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Printf("📋 Implementing #%d: %s\n", issue.Number, issue.Title)
+		result := &implementOutcome{Issue: issue.Number}
+		current.Result = result
+
+		opCtx, cancel = context.WithTimeout(ctx, apiTimeout)
+		repository, err := github.GetRepository(opCtx, owner, repo, token)
+		cancel()
+		if err != nil {
+			fatal(err)
+		}
+		base := repository.DefaultBranch
+
+		contextFiles, err := gatherContext(ctx, owner, repo, base, token, issue)
+		if err != nil {
+			fatalf("Failed to gather repository context: %v", err)
+		}
+		fmt.Printf("📂 Using %d file(s) as context\n", len(contextFiles))
+
+		fmt.Println("🤖 Asking AI for an implementation...")
+		opCtx, cancel = context.WithTimeout(ctx, llmTimeout)
+		changes, err := openai.ProposeChanges(opCtx, issue.Title, issue.Body, contextFiles, openaiKey)
+		cancel()
+		if err != nil {
+			fatalf("Failed to generate changes: %v", err)
+		}
+
+		files := make([]github.File, 0, len(changes.Files))
+		for _, change := range changes.Files {
+			if err := github.CheckPath(change.Path); err != nil {
+				fatalf("Refusing unsafe change: %v", err)
+			}
+			files = append(files, github.File{Path: change.Path, Content: []byte(change.Content), Delete: change.Delete})
+			if change.Delete {
+				fmt.Println("   🗑️ ", change.Path)
+			} else {
+				fmt.Println("   ✏️ ", change.Path)
+			}
+		}
+
+		branch := implementBranch
+		if branch == "" {
+			branch = fmt.Sprintf("ai/issue-%d-%s", issue.Number, slugify(issue.Title))
+		}
+
+		message := changes.CommitMessage
+		if message == "" {
+			message = issue.Title
+		}
+		message = fmt.Sprintf("%s (#%d)", message, issue.Number)
+
+		committer, err := newCommitter(owner, repo, token)
+		if err != nil {
+			fatal(err)
+		}
+		opCtx, cancel = context.WithTimeout(ctx, commitTimeout)
+		sha, err := committer.Commit(opCtx, branch, base, message, files)
+		cancel()
+		if err != nil {
+			fatalf("Failed to commit changes: %v", err)
+		}
+		fmt.Printf("✅ Committed %s to branch %s\n", sha, branch)
+		result.Commit = &commitOutcome{Branch: branch, SHA: sha}
+		for _, f := range files {
+			result.Commit.Files = append(result.Commit.Files, f.Path)
+		}
+
+		opCtx, cancel = context.WithTimeout(ctx, apiTimeout)
+		defer cancel()
+		pr, err := github.CreatePullRequest(opCtx, owner, repo, token, github.NewPullRequest{
+			Title:  issue.Title,
+			Head:   branch,
+			Base:   base,
+			Body:   changes.Summary,
+			Draft:  implementDraft,
+			Closes: []int{issue.Number},
+		})
+		if err != nil {
+			fatalf("Failed to open pull request: %v", err)
+		}
+		result.PullRequest = pr.HTMLURL
+
+		if err := github.RequestReviewers(opCtx, owner, repo, pr.Number, token, implementReviewers, nil); err != nil {
+			fmt.Println("❌ Failed to request reviewers:", err)
+			partialFailure("failed to request reviewers: %v", err)
+		}
+		if err := github.AddLabels(opCtx, owner, repo, pr.Number, token, implementLabels); err != nil {
+			fmt.Println("❌ Failed to add labels:", err)
+			partialFailure("failed to add labels: %v", err)
+		}
+
+		fmt.Println("✅ Pull request opened:", pr.HTMLURL)
+	},
+}
+
+// implementOutcome is the result of implement in the JSON report
+type implementOutcome struct {
+	Issue       int            `json:"issue"`
+	Commit      *commitOutcome `json:"commit,omitempty"`
+	PullRequest string         `json:"pull_request,omitempty"`
+}
+
+// gatherContext picks the repository files most relevant to the issue and downloads them,
+// staying within the context limits
+func gatherContext(ctx context.Context, owner, repo, ref string, token github.TokenSource, issue *github.Issue) ([]openai.ContextFile, error) {
+	opCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+	entries, err := github.ListTree(opCtx, owner, repo, ref, token)
+	cancel()
+	if errors.Is(err, github.ErrTreeTruncated) {
+		warn("The repository tree was truncated; context is picked from the %d files GitHub listed", len(entries))
+	} else if err != nil {
+		return nil, err
+	}
+
+	keywords := strings.Fields(strings.ToLower(issue.Title + " " + issue.Body))
+	type candidate struct {
+		entry github.TreeEntry
+		score int
+	}
+	var candidates []candidate
+	for _, entry := range entries {
+		if entry.Type != "blob" || entry.Size > maxContextFileSize || !isContextFile(entry.Path) {
+			continue
+		}
+		candidates = append(candidates, candidate{entry, relevance(entry.Path, keywords)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].entry.Path < candidates[j].entry.Path
+	})
+
+	var files []openai.ContextFile
+	total := 0
+	for _, c := range candidates {
+		if len(files) == maxContextFiles {
+			break
+		}
+		if total+c.entry.Size > maxContextBytes {
+			continue
+		}
+		opCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+		content, err := github.GetBlob(opCtx, owner, repo, c.entry.SHA, token)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", c.entry.Path, err)
+		}
+		files = append(files, openai.ContextFile{Path: c.entry.Path, Content: string(content)})
+		total += c.entry.Size
+	}
+	return files, nil
+}
+
+func isContextFile(p string) bool {
+	for _, dir := range skippedDirs {
+		if strings.HasPrefix(p, dir) || strings.Contains(p, "/"+dir) {
+			return false
+		}
+	}
+	return !skippedExts[strings.ToLower(path.Ext(p))]
+}
+
+// relevance scores a path by how many issue keywords appear in it. Top-level files
+// (README, manifests) get a small boost since they describe the project as a whole.
+func relevance(p string, keywords []string) int {
+	lower := strings.ToLower(p)
+	score := 0
+	if !strings.Contains(p, "/") {
+		score++
+	}
+	for _, kw := range keywords {
+		if len(kw) > 3 && strings.Contains(lower, kw) {
+			score += 2
+		}
+	}
+	return score
+}
+
+// slugify turns an issue title into a short branch-name friendly string
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func init() {
+	implementCmd.Flags().StringVarP(&implementRepo, "repo", "r", "", "Name of the GitHub repository the issue belongs to")
+	implementCmd.Flags().StringVarP(&implementBranch, "branch", "b", "", "Feature branch to commit to (default ai/issue-<number>-<title>)")
+	implementCmd.Flags().BoolVar(&implementDraft, "draft", false, "Open the pull request as a draft")
+	implementCmd.Flags().StringSliceVar(&implementReviewers, "reviewer", nil, "GitHub users to request a review from (repeatable)")
+	implementCmd.Flags().StringSliceVar(&implementLabels, "label", nil, "Labels to add to the pull request (repeatable)")
+	implementCmd.MarkFlagRequired("repo")
+	rootCmd.AddCommand(implementCmd)
+}
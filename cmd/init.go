@@ -1,21 +1,38 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
 	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
 	"github.com/TheAlonso95/ai-dev-agent/internal/github"
 	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/plan"
+	"github.com/TheAlonso95/ai-dev-agent/internal/scaffold"
 )
 
 var (
-	repoName string
-	stack    string
+	repoName     string
+	stack        string
+	useExisting  bool
+	onDuplicate  string
+	templateArg  string
+	templateDir  string
+	templateRepo string
+	assumeYes    bool
+	generateCI   bool
+	actionsAllow []string
+	settingsPath string
+	planID       string
+
+	withCommunity  bool
+	licenseKey     string
+	copyrightOwner string
+	contactEmail   string
 )
 
 var initCmd = &cobra.Command{
@@ -24,72 +41,294 @@ var initCmd = &cobra.Command{
 	Long: `This command creates a GitHub repo and uses AI to break your idea into dev tasks.
 		Example:
   		aiagent init "Pomodoro timer web app" --name pomodoro-timer --stack "Go, SQLite, React"`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		idea := args[0]
-		err := godotenv.Load()
+		// A saved planning session supplies the idea, stack, name and tasks
+		var session *plan.Session
+		var idea string
+		switch {
+		case planID != "":
+			var err error
+			if session, err = plan.Load(planID); err != nil {
+				fatal(err)
+			}
+			idea = session.Idea
+			if stack == "" {
+				stack = session.Stack
+			}
+			if repoName == "" {
+				repoName = session.Name
+			}
+			if len(args) == 1 && args[0] != idea {
+				fatalf("Idea %q doesn't match the planning session's %q", args[0], idea)
+			}
+		case len(args) == 1:
+			idea = args[0]
+		default:
+			fatal("Give an idea, or a planning session with --plan")
+		}
+		if err := validateDuplicatePolicy(onDuplicate); err != nil {
+			fatal(err)
+		}
+		source, err := resolveScaffold(stack)
 		if err != nil {
-			log.Fatal("Error loading .env file")
+			fatal(err)
 		}
 
-		token := os.Getenv("GITHUB_TOKEN")
-		openaiKey := os.Getenv("OPENAI_API_KEY")
-		owner := os.Getenv("GITHUB_USERNAME")
+		// Repository settings come from --settings, falling back to the configuration
+		settings := cfg.RepositorySettings
+		if settingsPath != "" {
+			loaded, err := loadSettings(settingsPath)
+			if err != nil {
+				fatal(err)
+			}
+			settings = &loaded
+		}
+
+		if err := requireCredentials(cmd.Context(), config.FieldGitHubToken, config.FieldGitHubUsername, config.FieldOpenAIAPIKey); err != nil {
+			fatal(err)
+		}
+
+		ctx := cmd.Context()
+		token := ghToken
+		openaiKey := cfg.OpenAIAPIKey
+		owner := cfg.GitHubUsername
 		projectName := sanitizeRepoName(repoName)
 		if projectName == "" {
 			projectName = "ai-" + sanitizeRepoName(idea)
 		}
 
-		fmt.Println("Creating project with name:", repoName, projectName)
-		err = github.CreateRepo(projectName, token)
+		// Everything committed later is checked now, so bad input fails init before the
+		// repository exists
+		vars := scaffold.NewVars(projectName, owner, idea, stack)
+		if err := checkLocalTemplate(&source, vars); err != nil {
+			fatal(err)
+		}
+		// A template repository brings its own default branch, so its workflows are
+		// rendered once the repository exists
+		branch := "main"
+		var workflows []github.File
+		if templateRepo == "" {
+			if workflows, err = workflowFiles(vars, branch); err != nil {
+				fatalf("Failed to generate CI workflows: %v", err)
+			}
+		}
+		community, err := communityFiles(ctx, vars, token)
 		if err != nil {
-			log.Fatal(err)
+			fatalf("Failed to generate community files: %v", err)
 		}
 
-		finalPrompt := fmt.Sprintf("Build '%s' using %s. Break it into actionable tasks as JSON...", idea, stack)
-		tasks, err := openai.AskForTasks(finalPrompt, openaiKey)
-		if err != nil {
-			log.Fatal(err)
+		// The result is filled in as the work is done, so a failure reports how far it got
+		result := &initOutcome{
+			Repository: owner + "/" + projectName,
+			URL:        fmt.Sprintf("https://github.com/%s/%s", owner, projectName),
+		}
+		current.Result = result
+
+		// Without a saved session the plan is made in one shot, and saved so it can
+		// be refined with 'plan --resume' later
+		if session == nil {
+			session = plan.New(projectName, idea, stack)
+			var err error
+			if session.Brief, err = clarify(ctx, idea, stack, openaiKey, !assumeYes); err != nil {
+				fatal(err)
+			}
+			if err := startPlan(ctx, session, openaiKey); err != nil {
+				fatal(err)
+			}
+			// The saved plan is only a convenience for later, so init goes on without it
+			if err := session.Save(); err != nil {
+				warn("failed to save the plan: %v", err)
+			} else {
+				fmt.Println("💾 Plan saved as", session.ID)
+				result.PlanID = session.ID
+			}
+		} else {
+			result.PlanID = session.ID
 		}
+		tasks := session.Tasks
 
-		for _, task := range tasks {
-			err := github.CreateIssue(owner, projectName, token, task)
+		// Tasks are reviewed before anything is created, so quitting leaves no trace
+		if !assumeYes {
+			project := fmt.Sprintf("%s (stack: %s)", idea, stack)
+			var err error
+			if tasks, err = reviewTasks(ctx, tasks, project, openaiKey); err != nil {
+				fatal(err)
+			}
+		}
+
+		switch {
+		case useExisting:
+			fmt.Println("Using existing repository:", projectName)
+		case templateRepo != "":
+			fmt.Printf("Creating project %s from template %s\n", projectName, templateRepo)
+			opCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+			repo, err := github.GenerateFromTemplate(opCtx, templateRepo, owner, projectName, false, token)
+			cancel()
+			if err != nil {
+				fatal(err)
+			}
+			if repo.DefaultBranch != "" {
+				branch = repo.DefaultBranch
+			}
+			if err := github.WaitForBranch(ctx, owner, projectName, branch, token, 30*time.Second); err != nil {
+				fatal(err)
+			}
+		default:
+			fmt.Println("Creating project with name:", repoName, projectName)
+			opCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+			err := github.CreateRepo(opCtx, projectName, token)
+			cancel()
 			if err != nil {
-				log.Println("Failed to create issue:", err)
+				fatal(err)
 			}
 		}
 
+		tasks, duplicates, err := filterDuplicates(ctx, owner, projectName, token, onDuplicate, tasks)
+		if err != nil {
+			fatal(err)
+		}
+		printDuplicates(duplicates)
+		result.Duplicates = duplicateOutcomes(duplicates)
+
+		result.Issues = issueOutcomes(fileIssues(ctx, owner, projectName, token, tasks))
+		if ctx.Err() != nil {
+			fatal(ctx.Err())
+		}
+
 		fmt.Println("✅ Project setup complete:", repoName)
 
 		fmt.Println("📝 Generating README.md via AI...")
-		readme, err := openai.GenerateReadme(projectName, idea, stack, openaiKey)
+		onDelta, done := echoProgress()
+		llmCtx, cancel := context.WithTimeout(ctx, llmTimeout)
+		readme, err := openai.GenerateReadme(llmCtx, projectName, idea, stack, openaiKey, onDelta)
+		done()
+		cancel()
 		if err != nil {
-			log.Fatalf("Failed to generate README: %v", err)
+			fatalf("Failed to generate README: %v", err)
 		}
 
-		file := github.File{
+		files := []github.File{{
 			Path:    "README.md",
-			Content: readme,
+			Content: []byte(readme),
+		}}
+		message := "docs: add README.md"
+
+		var parts []string
+		scaffolded, origin, err := scaffoldFiles(ctx, source, vars, openaiKey)
+		if err != nil {
+			fatalf("Failed to scaffold project: %v", err)
+		}
+		if len(scaffolded) > 0 {
+			files = mergeFiles(scaffolded, files)
+			parts = append(parts, origin+" scaffold")
+		}
+
+		if templateRepo != "" {
+			if workflows, err = workflowFiles(vars, branch); err != nil {
+				fatalf("Failed to generate CI workflows: %v", err)
+			}
+		}
+		if len(workflows) > 0 {
+			files = mergeFiles(files, workflows)
+			parts = append(parts, "CI workflows")
+		}
+
+		if len(community) > 0 {
+			files = mergeFiles(files, community)
+			parts = append(parts, "community files")
 		}
 
-		// The repository is empty at this point, so we need to initialize it
-		// CreateBranchAndCommit will internally handle the empty repository case
-		err = github.CreateBranchAndCommit(projectName, []github.File{file}, token)
+		if len(parts) > 0 {
+			message = "Initial commit: README, " + strings.Join(parts, ", ")
+		}
+
+		// The repository may still be empty at this point; committers start the
+		// main branch from scratch in that case
+		committer, err := newCommitter(owner, projectName, token)
+		if err != nil {
+			fatal(err)
+		}
+		commitCtx, cancel := context.WithTimeout(ctx, commitTimeout)
+		sha, err := committer.Commit(commitCtx, branch, branch, message, files)
+		cancel()
 		if err != nil {
-			log.Fatalf("Failed to commit README: %v", err)
+			fatalf("Failed to commit README: %v", err)
 		}
+		result.Commit = &commitOutcome{Branch: branch, SHA: sha}
+		for _, f := range files {
+			result.Commit.Files = append(result.Commit.Files, f.Path)
+		}
+
+		fmt.Printf("✅ %d file(s) committed to %s branch in repo: %s\n", len(files), branch, projectName)
 
-		fmt.Println("✅ README committed to main branch in repo:", projectName)
+		if settings != nil {
+			fmt.Println("🔒 Applying repository settings...")
+			changes, err := applySettings(ctx, owner, projectName, token, *settings, false)
+			if err != nil {
+				fatalf("Failed to apply repository settings: %v", err)
+			}
+			result.Settings = changes
+		}
 	},
 }
 
+// initOutcome is the result of init in the JSON report
+type initOutcome struct {
+	Repository string                 `json:"repository"`
+	URL        string                 `json:"url"`
+	PlanID     string                 `json:"plan_id,omitempty"`
+	Issues     []issueOutcome         `json:"issues"`
+	Duplicates []duplicateOutcome     `json:"duplicates,omitempty"`
+	Commit     *commitOutcome         `json:"commit,omitempty"`
+	Settings   []github.SettingChange `json:"settings,omitempty"`
+}
+
+// commitOutcome is a commit made by a command, in the JSON report
+type commitOutcome struct {
+	Branch string   `json:"branch"`
+	SHA    string   `json:"sha"`
+	Files  []string `json:"files,omitempty"`
+}
+
+// sanitizeRepoName lowercases name and replaces every run of characters GitHub doesn't
+// allow in repository names with a dash
 func sanitizeRepoName(name string) string {
-	// Replace spaces with dashes, lowercase, remove special chars (basic)
-	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.Trim(b.String(), "-.")
 }
 
 func init() {
 	initCmd.Flags().StringVarP(&repoName, "name", "n", "", "Custom name for the GitHub repository")
 	initCmd.Flags().StringVarP(&stack, "stack", "s", "", "Tech stack (e.g. 'Go, PostgreSQL, React')")
+	initCmd.Flags().StringVarP(&templateArg, "template", "t", "", "Project template to scaffold, 'ai' to let AI propose the files (default: picked from --stack, 'none' to skip)")
+	initCmd.Flags().StringVar(&templateDir, "template-dir", "", "Local template directory to render instead of a built-in template")
+	initCmd.Flags().StringVar(&templateRepo, "template-repo", "", "Create the repository from a GitHub template repository (owner/name)")
+	initCmd.Flags().BoolVar(&generateCI, "ci", true, "Generate GitHub Actions workflows for the stack")
+	initCmd.Flags().StringSliceVar(&actionsAllow, "actions-allow", scaffold.DefaultActionsAllowlist, "Actions that may be referenced without a pinned commit SHA (patterns like owner/*)")
+	initCmd.Flags().BoolVar(&withCommunity, "community", false, "Add LICENSE, CODE_OF_CONDUCT, CONTRIBUTING, SECURITY, issue forms and a PR template")
+	initCmd.Flags().StringVar(&licenseKey, "license", "mit", "License for --community, as a GitHub license key (e.g. mit, apache-2.0, gpl-3.0) or 'none'")
+	initCmd.Flags().StringVar(&copyrightOwner, "copyright-owner", "", "Copyright holder named in the license (default: the GitHub username)")
+	initCmd.Flags().StringVar(&contactEmail, "contact", "", "Email address for security reports and code of conduct issues")
+	initCmd.Flags().StringVar(&settingsPath, "settings", "", "Repository settings YAML file to apply after creation (default: repository_settings from the config)")
+	initCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Don't review planned tasks or ask for confirmation before committing generated files")
+	initCmd.Flags().BoolVar(&useExisting, "existing", false, "Use an existing repository instead of creating one")
+	initCmd.Flags().BoolVar(&clarifyIdea, "clarify", true, "Answer the planner's clarifying questions before planning (skipped with --yes unless --answers is given)")
+	initCmd.Flags().StringVar(&answersPath, "answers", "", "YAML file answering clarifying questions by topic (platform, auth, persistence, ...)")
+	initCmd.Flags().StringVar(&planID, "plan", "", "Use the tasks of a saved planning session (see 'plan') instead of planning anew")
+	initCmd.Flags().IntVar(&issueWorkers, "concurrency", 4, "How many issues to fill in at once; numbers always follow the plan's order")
+	initCmd.Flags().StringVar(&onDuplicate, "on-duplicate", duplicateSkip, "What to do with tasks matching existing issues: skip, comment or warn")
+	initCmd.MarkFlagsMutuallyExclusive("template", "template-dir")
+	initCmd.MarkFlagsMutuallyExclusive("existing", "template-repo")
 	rootCmd.AddCommand(initCmd)
 }
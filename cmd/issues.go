@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+	"github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+var issueWorkers int
+
+// issueOutcome is a filed task in the JSON report
+type issueOutcome struct {
+	Task   string `json:"task"`
+	Number int    `json:"number,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// fileIssues creates an issue per task through a pool of issueWorkers and prints the
+// outcome of each as a table. Failed issues are recorded as a partial failure.
+func fileIssues(ctx context.Context, owner, repo string, token github.TokenSource, list []tasks.Task) []github.IssueResult {
+	if len(list) == 0 {
+		return nil
+	}
+	fmt.Printf("📌 Creating %d issue(s)...\n", len(list))
+
+	// Bound the batch by one API timeout per issue, however the work is spread out
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(len(list)+1)*apiTimeout)
+	defer cancel()
+	results := github.CreateIssues(ctx, owner, repo, token, list, issueWorkers)
+	printIssueTable(results)
+	for _, r := range results {
+		if r.Err != nil {
+			partialFailure("issue %q: %v", r.Task.Title, r.Err)
+		}
+	}
+	return results
+}
+
+func issueOutcomes(results []github.IssueResult) []issueOutcome {
+	outcomes := make([]issueOutcome, 0, len(results))
+	for _, r := range results {
+		o := issueOutcome{Task: r.Task.Title}
+		if r.Issue != nil {
+			o.Number, o.URL = r.Issue.Number, r.Issue.HTMLURL
+		}
+		if r.Err != nil {
+			o.Error = r.Err.Error()
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes
+}
+
+func printIssueTable(results []github.IssueResult) {
+	width := len("Task")
+	for _, r := range results {
+		width = max(width, min(utf8.RuneCountInString(r.Task.Title), 50))
+	}
+
+	fmt.Printf("\n  %-6s  %-*s  %s\n", "Issue", width, "Task", "Status")
+	failed := 0
+	for _, r := range results {
+		number := "-"
+		if r.Issue != nil {
+			number = fmt.Sprintf("#%d", r.Issue.Number)
+		}
+		title := r.Task.Title
+		if runes := []rune(title); len(runes) > width {
+			title = string(runes[:width-3]) + "..."
+		}
+
+		status := "✅ created"
+		switch {
+		case r.Err != nil && r.Issue != nil:
+			status = "⚠️ created without body and labels: " + r.Err.Error()
+			failed++
+		case r.Err != nil:
+			status = "❌ " + r.Err.Error()
+			failed++
+		}
+		// %-*s pads by bytes, so pad multi-byte titles by their rune count
+		pad := width - utf8.RuneCountInString(title)
+		fmt.Printf("  %-6s  %s%*s  %s\n", number, title, pad, "", status)
+	}
+	if failed > 0 {
+		fmt.Printf("\n❌ %d of %d issue(s) failed\n", failed, len(results))
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+)
+
+var (
+	verbose   bool
+	logFormat string
+	traceHTTP bool
+)
+
+// startLogging sends diagnostics to stderr in the --log-format, and puts the logger in
+// the command's context for the packages it calls. --verbose adds debug messages, such as every API request;
+// --trace-http also dumps the requests and responses, with credentials redacted. Only
+// http.DefaultClient is traced: the git binary used by --git-backend git talks to GitHub
+// itself, so only the commands it runs are logged.
+func startLogging(cmd *cobra.Command) error {
+	logger, err := logging.New(os.Stderr, logFormat, verbose || traceHTTP)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	cmd.SetContext(logging.WithLogger(cmd.Context(), logger))
+
+	if verbose || traceHTTP {
+		http.DefaultClient.Transport = &logging.Transport{
+			Base:   http.DefaultClient.Transport,
+			Logger: logger,
+			Bodies: traceHTTP,
+		}
+	}
+	return nil
+}
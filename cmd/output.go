@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+)
+
+// Output formats accepted by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// Report statuses, and the exit code of each
+const (
+	statusOK      = "ok"      // exit 0
+	statusFailed  = "failed"  // exit 1
+	statusPartial = "partial" // exit 2: some of the work was done
+)
+
+var outputFormat string
+
+// stdout is where the JSON report is written. In JSON mode os.Stdout is pointed at
+// stderr, so status lines and prompts never mix with the report.
+var stdout = os.Stdout
+
+// report is the result document of a command, printed with --output json
+type report struct {
+	Command  string   `json:"command"`
+	Status   string   `json:"status"`
+	Warnings []string `json:"warnings,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+	// Result is the command-specific outcome
+	Result   any       `json:"result,omitempty"`
+	LLMUsage *llmUsage `json:"llm_usage,omitempty"`
+}
+
+// current is the report of the running command
+var current = &report{Status: statusOK}
+
+// startOutput validates --output and, for JSON, moves human-readable output to stderr
+func startOutput(cmd *cobra.Command) error {
+	current.Command = cmd.CommandPath()
+	switch outputFormat {
+	case outputText:
+	case outputJSON:
+		os.Stdout = os.Stderr
+	default:
+		return fmt.Errorf("invalid --output value %q (want %s or %s)", outputFormat, outputText, outputJSON)
+	}
+	return nil
+}
+
+// jsonRequested scans the command line for --output json, for errors cobra returns
+// before it parses the flags
+func jsonRequested(args []string) bool {
+	format := ""
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		switch {
+		case arg == "-o" || arg == "--output":
+			if i+1 < len(args) {
+				format = args[i+1]
+			}
+		case strings.HasPrefix(arg, "--output="):
+			format = strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "-o"):
+			format = strings.TrimPrefix(strings.TrimPrefix(arg, "-o"), "=")
+		}
+	}
+	return format == outputJSON
+}
+
+// warn records a problem that didn't stop the command and prints it
+func warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	current.Warnings = append(current.Warnings, msg)
+	fmt.Println("⚠️ " + msg)
+}
+
+// partialFailure records an error after which the command carried on, so it finishes
+// with a partial status
+func partialFailure(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	current.Errors = append(current.Errors, msg)
+	if current.Status == statusOK {
+		current.Status = statusPartial
+	}
+}
+
+// fatal logs an error and ends the command, writing the report first
+func fatal(args ...any) {
+	fail(fmt.Sprint(args...))
+}
+
+// fatalf logs a formatted error and ends the command, writing the report first
+func fatalf(format string, args ...any) {
+	fail(fmt.Sprintf(format, args...))
+}
+
+func fail(msg string) {
+	current.Status = statusFailed
+	current.Errors = append(current.Errors, msg)
+	if outputFormat == outputJSON {
+		writeReport()
+	} else {
+		logError(msg)
+		printUsage()
+	}
+	os.Exit(exitCode())
+}
+
+// finish prints the LLM usage and the report, and exits non-zero when part of the
+// work failed
+func finish(cmd *cobra.Command, args []string) {
+	printUsage()
+	if outputFormat == outputJSON {
+		writeReport()
+	}
+	if code := exitCode(); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// logError reports an error to the user: as a log record with --log-format json, and
+// as plain text otherwise, since text records escape the newlines errors often contain
+func logError(msg string) {
+	if logFormat == logging.FormatJSON {
+		slog.Error(msg)
+		return
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+func writeReport() {
+	current.LLMUsage = usageReport()
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(current); err != nil {
+		slog.Error("failed to write the report", "error", err)
+	}
+}
+
+func exitCode() int {
+	switch current.Status {
+	case statusFailed:
+		return 1
+	case statusPartial:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/plan"
+	"github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+var planResume string
+
+var planCmd = &cobra.Command{
+	Use:   "plan [idea]",
+	Short: "Refine the task plan for an idea over several rounds of feedback",
+	Long: `Plan starts a conversation with the planner: it proposes tasks, you reply with
+feedback such as "merge the auth tasks" or "add observability", and it revises the
+whole list, showing what changed. Every round is saved, so a session can be resumed
+with --resume and finally applied with 'init --plan <id>'.
+
+	Example:
+  		aiagent plan "Pomodoro timer web app" --name pomodoro-timer --stack "Go, SQLite, React"`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireCredentials(cmd.Context(), config.FieldOpenAIAPIKey); err != nil {
+			fatal(err)
+		}
+
+		var session *plan.Session
+		switch {
+		case planResume != "" && len(args) > 0:
+			fatal("Pass either an idea or --resume, not both")
+		case planResume != "":
+			var err error
+			if session, err = plan.Load(planResume); err != nil {
+				fatal(err)
+			}
+			fmt.Printf("📂 Resuming %s: %s\n", session.ID, session.Idea)
+			printTasks(session.Tasks)
+		case len(args) == 1:
+			name := sanitizeRepoName(repoName)
+			if name == "" {
+				name = "ai-" + sanitizeRepoName(args[0])
+			}
+			session = plan.New(name, args[0], stack)
+			var err error
+			if session.Brief, err = clarify(cmd.Context(), session.Idea, session.Stack, cfg.OpenAIAPIKey, true); err != nil {
+				fatal(err)
+			}
+		default:
+			fatal("Give an idea to plan, or --resume a session (see 'plan list')")
+		}
+
+		if err := refinePlan(cmd.Context(), session, cfg.OpenAIAPIKey); err != nil {
+			fatal(err)
+		}
+		current.Result = planOutcomeOf(session)
+		fmt.Printf("\n💾 Session saved as %s\n", session.ID)
+		fmt.Printf("   Resume with:  aiagent plan --resume %s\n", session.ID)
+		fmt.Printf("   Apply with:   aiagent init --plan %s\n", session.ID)
+	},
+}
+
+var planListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved planning sessions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sessions, err := plan.List()
+		if err != nil {
+			fatal(err)
+		}
+		summaries := []planSummary{}
+		for _, s := range sessions {
+			summaries = append(summaries, planSummary{ID: s.ID, Idea: s.Idea, Tasks: len(s.Tasks), UpdatedAt: s.UpdatedAt})
+		}
+		current.Result = summaries
+		if len(sessions) == 0 {
+			fmt.Println("No saved planning sessions")
+			return
+		}
+		for _, s := range sessions {
+			fmt.Printf("%-40s %2d tasks  %s  %s\n", s.ID, len(s.Tasks), s.UpdatedAt.Format("2006-01-02 15:04"), s.Idea)
+		}
+	},
+}
+
+var planShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Print the tasks of a planning session",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		session, err := plan.Load(args[0])
+		if err != nil {
+			fatal(err)
+		}
+		current.Result = planOutcomeOf(session)
+		fmt.Printf("%s (stack: %s), %d revision(s)\n\n", session.Idea, session.Stack, session.Revisions)
+		if b := session.Brief.String(); b != "" {
+			fmt.Println(b)
+		}
+		for _, t := range session.Tasks {
+			fmt.Println(tasks.Format(t))
+		}
+	},
+}
+
+// planOutcome is a planning session in the JSON report, without its conversation
+type planOutcome struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Idea      string       `json:"idea"`
+	Stack     string       `json:"stack"`
+	Brief     *plan.Brief  `json:"brief,omitempty"`
+	Revisions int          `json:"revisions"`
+	Tasks     []tasks.Task `json:"tasks"`
+}
+
+// planSummary is a saved session in the JSON report of plan list
+type planSummary struct {
+	ID        string    `json:"id"`
+	Idea      string    `json:"idea"`
+	Tasks     int       `json:"tasks"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func planOutcomeOf(s *plan.Session) planOutcome {
+	return planOutcome{ID: s.ID, Name: s.Name, Idea: s.Idea, Stack: s.Stack, Brief: s.Brief, Revisions: s.Revisions, Tasks: s.Tasks}
+}
+
+// startPlan has the planner propose the first task list of a session. The caller saves
+// the session.
+func startPlan(ctx context.Context, session *plan.Session, openaiKey string) error {
+	messages, err := openai.PlanMessages(session.Idea, session.Stack, session.Brief.String())
+	if err != nil {
+		return err
+	}
+	onDelta, done := planProgress("🧠 Planning tasks...")
+	llmCtx, cancel := context.WithTimeout(ctx, llmTimeout)
+	messages, list, err := openai.ContinuePlan(llmCtx, messages, openaiKey, onDelta)
+	cancel()
+	done()
+	if err != nil {
+		return err
+	}
+	session.Messages, session.Tasks = messages, list
+	session.Revisions++
+	return nil
+}
+
+// refinePlan runs planning rounds until the user is done, saving the session after
+// every revision. A new session starts with the planner's first proposal.
+func refinePlan(ctx context.Context, session *plan.Session, openaiKey string) error {
+	if session.Revisions == 0 {
+		if err := startPlan(ctx, session, openaiKey); err != nil {
+			return err
+		}
+		if err := session.Save(); err != nil {
+			return err
+		}
+		printTasks(session.Tasks)
+	}
+
+	retry := false
+	for {
+		fmt.Print("\n💬 Feedback (empty to finish): ")
+		feedback, err := stdin.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		feedback = strings.TrimSpace(feedback)
+		if feedback == "" {
+			return nil
+		}
+
+		messages, err := openai.PlanFeedback(session.Messages, feedback)
+		if err != nil {
+			return err
+		}
+		onDelta, done := planProgress("🧠 Revising plan...")
+		llmCtx := ctx
+		if retry {
+			// Feedback after a failed round is a retry, which must reach the model
+			llmCtx = openai.Fresh(ctx)
+		}
+		llmCtx, cancel := context.WithTimeout(llmCtx, llmTimeout)
+		messages, list, err := openai.ContinuePlan(llmCtx, messages, openaiKey, onDelta)
+		cancel()
+		done()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if retry = err != nil; retry {
+			fmt.Println("❌ Failed to revise the plan:", err)
+			continue
+		}
+
+		changes := tasks.Diff(session.Tasks, list)
+		session.Messages, session.Tasks = messages, list
+		session.Revisions++
+		if err := session.Save(); err != nil {
+			return err
+		}
+		printTasks(session.Tasks)
+		printTaskDiff(changes)
+	}
+}
+
+func printTasks(list []tasks.Task) {
+	fmt.Println()
+	for i, t := range list {
+		labels := ""
+		if len(t.Labels) > 0 {
+			labels = " (" + strings.Join(t.Labels, ", ") + ")"
+		}
+		fmt.Printf("  %2d. %s%s\n", i+1, t.Title, labels)
+	}
+}
+
+func printTaskDiff(changes []tasks.Change) {
+	if len(changes) == 0 {
+		fmt.Println("\n📝 No changes")
+		return
+	}
+	fmt.Println("\n📝 Changes:")
+	for _, c := range changes {
+		switch c.Kind {
+		case tasks.Added:
+			fmt.Printf("  + %s\n", c.New.Title)
+		case tasks.Removed:
+			fmt.Printf("  - %s\n", c.Old.Title)
+		case tasks.Changed:
+			title := c.New.Title
+			if c.Old.Title != c.New.Title {
+				title = fmt.Sprintf("%s → %s", c.Old.Title, c.New.Title)
+			}
+			fmt.Printf("  ~ %s (%s)\n", title, strings.Join(c.Fields, ", "))
+		}
+	}
+}
+
+func init() {
+	planCmd.Flags().StringVarP(&repoName, "name", "n", "", "Name of the GitHub repository the plan is for")
+	planCmd.Flags().StringVarP(&stack, "stack", "s", "", "Tech stack (e.g. 'Go, PostgreSQL, React')")
+	planCmd.Flags().BoolVar(&clarifyIdea, "clarify", true, "Answer the planner's clarifying questions before planning")
+	planCmd.Flags().StringVar(&answersPath, "answers", "", "YAML file answering clarifying questions by topic (platform, auth, persistence, ...)")
+	planCmd.Flags().StringVar(&planResume, "resume", "", "Continue a saved session by ID")
+	planCmd.AddCommand(planListCmd, planShowCmd)
+	rootCmd.AddCommand(planCmd)
+}
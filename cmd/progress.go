@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// interactiveOutput reports whether stdout is a terminal, where progress can be redrawn
+func interactiveOutput() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// echoProgress prints a streamed response as it arrives. It returns nil when stdout
+// isn't a terminal, so logs only get the finished result.
+func echoProgress() (onDelta func(string), done func()) {
+	if !interactiveOutput() {
+		return nil, func() {}
+	}
+	printed := false
+	onDelta = func(delta string) {
+		printed = true
+		fmt.Print(delta)
+	}
+	done = func() {
+		if printed {
+			fmt.Println()
+		}
+	}
+	return onDelta, done
+}
+
+// planProgress counts the tasks of a streamed plan as the model writes them, redrawing
+// a single status line
+func planProgress(status string) (onDelta func(string), done func()) {
+	if !interactiveOutput() {
+		fmt.Println(status)
+		return nil, func() {}
+	}
+	var content strings.Builder
+	drafted := -1
+	draw := func() {
+		fmt.Printf("\r%s %d tasks drafted", status, drafted)
+	}
+	onDelta = func(delta string) {
+		content.WriteString(delta)
+		if n := strings.Count(content.String(), `"title"`); n != drafted {
+			drafted = n
+			draw()
+		}
+	}
+	done = func() { fmt.Println() }
+	fmt.Print(status)
+	return onDelta, done
+}
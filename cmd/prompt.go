@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on the terminal; anything but y/yes counts as no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// ask prompts for a free-form answer, returning def when the answer is empty
+func ask(question, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	answer, _ := stdin.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def
+	}
+	return answer
+}
+
+// askSecret prompts for a secret without echoing it. Input that isn't a terminal is
+// read as a plain line, so secrets can be piped in.
+func askSecret(question string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		answer, err := stdin.ReadString('\n')
+		if err != nil && answer == "" {
+			return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(question), err)
+		}
+		return strings.TrimSpace(answer), nil
+	}
+
+	fmt.Printf("%s: ", question)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+	"github.com/TheAlonso95/ai-dev-agent/internal/prompts"
+)
+
+var promptSet []string
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List, render and override the prompts sent to the model",
+	Long: `Every prompt is a text/template file. Put a file with the same name in the
+prompts directory of your config (e.g. ~/.config/aiagent/prompts/plan.system.tmpl)
+to replace the built-in one; 'prompts eject' copies a built-in prompt there to start from.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := prompts.List()
+		if err != nil {
+			fatal(err)
+		}
+		result := promptsOutcome{OverrideDir: prompts.OverrideDir(), Prompts: make([]promptOutcome, 0, len(list))}
+		for _, p := range list {
+			source := "built-in"
+			if p.Override != "" {
+				source = "override"
+			}
+			fmt.Printf("%-20s v%-3d %-9s %s\n", p.Name, p.Version, source, p.Description)
+			if p.Override != "" && p.Version < p.BuiltinVersion {
+				fmt.Printf("  ⚠️ %s is based on v%d; the built-in prompt is now v%d\n", p.Override, p.Version, p.BuiltinVersion)
+			}
+			result.Prompts = append(result.Prompts, promptOutcome{
+				Name:           p.Name,
+				Description:    p.Description,
+				Version:        p.Version,
+				BuiltinVersion: p.BuiltinVersion,
+				Override:       p.Override,
+			})
+		}
+		fmt.Println("\nOverrides are read from", prompts.OverrideDir())
+		current.Result = result
+	},
+}
+
+var promptsRenderCmd = &cobra.Command{
+	Use:   "render <name>",
+	Short: "Render a prompt with sample inputs",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data := map[string]any{}
+		for k, v := range prompts.Sample(args[0]) {
+			data[k] = v
+		}
+		for _, kv := range promptSet {
+			key, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				fatalf("--set takes key=value, got %q", kv)
+			}
+			data[key] = value
+		}
+
+		out, err := prompts.Render(args[0], data)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Println(out)
+		current.Result = map[string]string{"name": args[0], "prompt": out}
+	},
+}
+
+var promptsEjectCmd = &cobra.Command{
+	Use:   "eject <name>",
+	Short: "Copy a built-in prompt to the overrides directory for editing",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		source, err := prompts.Builtin(args[0])
+		if err != nil {
+			fatal(err)
+		}
+		path := filepath.Join(prompts.OverrideDir(), args[0]+".tmpl")
+		if _, err := os.Stat(path); err == nil {
+			fatalf("%s already exists", path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			fatal(err)
+		}
+		fmt.Println("✅ Wrote", path)
+		current.Result = map[string]string{"path": path}
+	},
+}
+
+// promptsOutcome is the result of prompts in the JSON report
+type promptsOutcome struct {
+	OverrideDir string          `json:"override_dir"`
+	Prompts     []promptOutcome `json:"prompts"`
+}
+
+// promptOutcome is one prompt and the template in use for it
+type promptOutcome struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Version        int    `json:"version"`
+	BuiltinVersion int    `json:"builtin_version"`
+	Override       string `json:"override,omitempty"`
+}
+
+// setPromptOverrides points the prompts package at the prompts directory of the config
+func setPromptOverrides() error {
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+	prompts.SetOverrideDir(filepath.Join(dir, "prompts"))
+	return nil
+}
+
+func init() {
+	promptsRenderCmd.Flags().StringArrayVar(&promptSet, "set", nil, "Override a sample input (key=value)")
+	promptsCmd.AddCommand(promptsRenderCmd, promptsEjectCmd)
+	rootCmd.AddCommand(promptsCmd)
+}
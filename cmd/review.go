@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+// errReviewAborted is returned when the user quits the review without filing anything
+var errReviewAborted = errors.New("review aborted, no issues were filed")
+
+// Review states of a planned task
+const (
+	reviewPending  = " "
+	reviewAccepted = "✓"
+	reviewRejected = "✗"
+)
+
+type reviewItem struct {
+	task   tasks.Task
+	status string
+}
+
+const reviewHelp = `Commands (N is a task number, several may be given where it makes sense):
+  a N... | a all   accept            r N... | r all   reject
+  v N              view in full      e N              edit in $EDITOR
+  m N POS          move to POS       s N              split with AI
+  g N [feedback]   regenerate with AI, following your feedback
+  d                done: file the accepted tasks
+  q                quit without filing anything`
+
+// reviewTasks walks the user through the planned tasks and returns the accepted ones in
+// the chosen order. project describes the idea and stack for AI edits.
+func reviewTasks(ctx context.Context, planned []tasks.Task, project, openaiKey string) ([]tasks.Task, error) {
+	items := make([]reviewItem, len(planned))
+	for i, t := range planned {
+		items[i] = reviewItem{task: t, status: reviewPending}
+	}
+
+	fmt.Println("\n🔎 Review the planned tasks before they are filed as issues")
+	fmt.Println(reviewHelp)
+	for {
+		printReview(items)
+		fmt.Print("review> ")
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w (no input; use --yes to file tasks without review)", errReviewAborted)
+			}
+			return nil, err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		command, args := fields[0], fields[1:]
+
+		switch command {
+		case "a", "r":
+			status := reviewAccepted
+			if command == "r" {
+				status = reviewRejected
+			}
+			indexes, err := reviewIndexes(args, len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			for _, i := range indexes {
+				items[i].status = status
+			}
+
+		case "v":
+			i, err := reviewIndex(args, len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			fmt.Println()
+			fmt.Print(tasks.Format(items[i].task))
+
+		case "e":
+			i, err := reviewIndex(args, len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			edited, err := editTask(items[i].task)
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			items[i].task = edited
+
+		case "m":
+			if len(args) != 2 {
+				fmt.Println("❌ usage: m N POS")
+				continue
+			}
+			from, err := reviewIndex(args[:1], len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			to, err := reviewIndex(args[1:], len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			item := items[from]
+			items = append(items[:from], items[from+1:]...)
+			items = append(items[:to], append([]reviewItem{item}, items[to:]...)...)
+
+		case "s":
+			i, err := reviewIndex(args, len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			fmt.Println("🧠 Splitting task...")
+			llmCtx, cancel := context.WithTimeout(ctx, llmTimeout)
+			split, err := openai.SplitTask(llmCtx, items[i].task, project, openaiKey)
+			cancel()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err != nil {
+				fmt.Println("❌ Failed to split task:", err)
+				continue
+			}
+			replacement := make([]reviewItem, len(split))
+			for j, t := range split {
+				replacement[j] = reviewItem{task: t, status: reviewPending}
+			}
+			items = append(items[:i], append(replacement, items[i+1:]...)...)
+
+		case "g":
+			i, err := reviewIndex(args[:min(len(args), 1)], len(items))
+			if err != nil {
+				fmt.Println("❌", err)
+				continue
+			}
+			feedback := strings.Join(args[1:], " ")
+			if feedback == "" {
+				feedback = ask("What should change", "")
+			}
+			fmt.Println("🧠 Regenerating task...")
+			// Asking again for the same change should get a new answer, not the cached one
+			llmCtx, cancel := context.WithTimeout(openai.Fresh(ctx), llmTimeout)
+			revised, err := openai.ReviseTask(llmCtx, items[i].task, feedback, project, openaiKey)
+			cancel()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err != nil {
+				fmt.Println("❌ Failed to regenerate task:", err)
+				continue
+			}
+			items[i] = reviewItem{task: revised, status: reviewPending}
+
+		case "d":
+			var accepted []tasks.Task
+			pending := 0
+			for _, item := range items {
+				switch item.status {
+				case reviewAccepted:
+					accepted = append(accepted, item.task)
+				case reviewPending:
+					pending++
+				}
+			}
+			if pending > 0 && !confirm(fmt.Sprintf("%d task(s) are still pending and won't be filed. Continue?", pending)) {
+				continue
+			}
+			return accepted, nil
+
+		case "q":
+			return nil, errReviewAborted
+
+		case "h", "?", "help":
+			fmt.Println(reviewHelp)
+
+		default:
+			fmt.Printf("❌ Unknown command %q\n%s\n", command, reviewHelp)
+		}
+	}
+}
+
+func printReview(items []reviewItem) {
+	fmt.Println()
+	for i, item := range items {
+		labels := ""
+		if len(item.task.Labels) > 0 {
+			labels = " (" + strings.Join(item.task.Labels, ", ") + ")"
+		}
+		fmt.Printf("  %2d. [%s] %s%s\n", i+1, item.status, item.task.Title, labels)
+	}
+}
+
+// reviewIndex parses a single 1-based task number into an index
+func reviewIndex(args []string, count int) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected one task number")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > count {
+		return 0, fmt.Errorf("%q is not a task number between 1 and %d", args[0], count)
+	}
+	return n - 1, nil
+}
+
+// reviewIndexes parses task numbers, or "all", into indexes
+func reviewIndexes(args []string, count int) ([]int, error) {
+	if len(args) == 1 && args[0] == "all" {
+		all := make([]int, count)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+	if len(args) == 0 {
+		return nil, errors.New("expected task numbers or 'all'")
+	}
+	indexes := make([]int, 0, len(args))
+	for _, arg := range args {
+		i, err := reviewIndex([]string{arg}, count)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes, nil
+}
+
+// editTask opens the task in the user's editor and parses the result
+func editTask(task tasks.Task) (tasks.Task, error) {
+	f, err := os.CreateTemp("", "aiagent-task-*.md")
+	if err != nil {
+		return tasks.Task{}, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(tasks.Format(task)); err != nil {
+		f.Close()
+		return tasks.Task{}, err
+	}
+	f.Close()
+
+	if err := runEditor(f.Name()); err != nil {
+		return tasks.Task{}, err
+	}
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return tasks.Task{}, err
+	}
+	return tasks.Parse(string(content))
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+	return nil
+}
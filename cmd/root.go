@@ -5,9 +5,15 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
 )
 
 
@@ -25,13 +31,37 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: loadConfig,
+	PersistentPostRun: finish,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Ctrl-C cancels the running operation; a second Ctrl-C kills the process, e.g. while
+	// waiting for input
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		signal.Stop(interrupt)
+		fmt.Fprintln(os.Stderr, "\n⏹️ Cancelling... (press Ctrl-C again to quit immediately)")
+		cancel()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
+		// Errors such as an unknown command are returned before flags are parsed
+		if outputFormat == outputJSON || jsonRequested(os.Args[1:]) {
+			outputFormat = outputJSON
+			if current.Command == "" {
+				current.Command = rootCmd.CommandPath()
+			}
+			fail(err.Error())
+		}
 		os.Exit(1)
 	}
 }
@@ -41,7 +71,14 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ai-dev-agent.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/aiagent/config.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&cfgProfile, "profile", "p", "", "config profile to use (default is the file's default_profile)")
+	rootCmd.PersistentFlags().StringVar(&gitBackend, "git-backend", backendAPI, "How commits are written: api (GitHub Git Data API) or git (local git binary)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text, or json for one result document on stdout")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log debug messages, such as every API request and git command, to stderr; with --git-backend git, git's own HTTP traffic is not logged")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "Log format: text or json")
+	rootCmd.PersistentFlags().BoolVar(&traceHTTP, "trace-http", false, "Log every GitHub and OpenAI HTTP request and response in full, with credentials redacted; not the git CLI's traffic with --git-backend git")
+	rootCmd.PersistentFlags().Float64Var(&maxCost, "max-cost", 0, "Abort before LLM calls would cost more than this many USD (0 means no limit)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
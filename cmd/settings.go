@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+var (
+	settingsRepo   string
+	settingsFile   string
+	settingsDryRun bool
+)
+
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Manage repository settings and branch protection as code",
+	Long: `Repository settings are described in a YAML file:
+
+  repository:
+    allow_squash_merge: true
+    allow_merge_commit: false
+    allow_rebase_merge: false
+    delete_branch_on_merge: true
+    has_wiki: false
+  branch_protection:
+    - branch: main
+      required_reviews: 1
+      required_status_checks: [build]
+      strict_status_checks: true`,
+}
+
+var settingsDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how the repository differs from the settings file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSettings(cmd.Context(), true)
+	},
+}
+
+var settingsApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the settings file to the repository",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSettings(cmd.Context(), settingsDryRun)
+	},
+}
+
+func runSettings(ctx context.Context, dryRun bool) {
+	if err := requireCredentials(ctx, config.FieldGitHubToken, config.FieldGitHubUsername); err != nil {
+		fatal(err)
+	}
+	token := ghToken
+	owner := cfg.GitHubUsername
+
+	settings, err := loadSettings(settingsFile)
+	if err != nil {
+		fatal(err)
+	}
+	changes, err := applySettings(ctx, owner, settingsRepo, token, settings, dryRun)
+	if err != nil {
+		fatal(err)
+	}
+	current.Result = settingsOutcome{Repository: owner + "/" + settingsRepo, DryRun: dryRun, Changes: changes}
+}
+
+// settingsOutcome is the result of settings diff and apply in the JSON report
+type settingsOutcome struct {
+	Repository string                 `json:"repository,omitempty"`
+	DryRun     bool                   `json:"dry_run"`
+	Changes    []github.SettingChange `json:"changes"`
+}
+
+// loadSettings reads a settings file, rejecting unknown keys so typos don't go unnoticed
+func loadSettings(path string) (github.Settings, error) {
+	var settings github.Settings
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&settings); err != nil {
+		return settings, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, p := range settings.BranchProtection {
+		if p.Branch == "" {
+			return settings, fmt.Errorf("%s: branch_protection entry without a branch", path)
+		}
+	}
+	return settings, nil
+}
+
+// applySettings prints the difference between the repository and settings and, unless
+// dryRun is set, applies it. It returns the changes.
+func applySettings(ctx context.Context, owner, repo string, token github.TokenSource, settings github.Settings, dryRun bool) ([]github.SettingChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, batchTimeout)
+	defer cancel()
+
+	var changes []github.SettingChange
+	var err error
+	if dryRun {
+		changes, err = github.DiffSettings(ctx, owner, repo, token, settings)
+	} else {
+		changes, err = github.ApplySettings(ctx, owner, repo, token, settings)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("✅ Repository settings are up to date")
+		return changes, nil
+	}
+
+	resource := ""
+	for _, c := range changes {
+		if c.Resource != resource {
+			resource = c.Resource
+			fmt.Println(resource)
+		}
+		fmt.Printf("  ~ %s: %s → %s\n", c.Field, valueOrUnset(c.Current), c.Desired)
+	}
+	if dryRun {
+		fmt.Printf("📝 %d change(s) would be applied\n", len(changes))
+	} else {
+		fmt.Printf("✅ Applied %d change(s)\n", len(changes))
+	}
+	return changes, nil
+}
+
+func valueOrUnset(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	return v
+}
+
+func init() {
+	for _, c := range []*cobra.Command{settingsDiffCmd, settingsApplyCmd} {
+		c.Flags().StringVarP(&settingsRepo, "repo", "r", "", "Name of the GitHub repository")
+		c.Flags().StringVarP(&settingsFile, "file", "f", "", "Settings YAML file")
+		c.MarkFlagRequired("repo")
+		c.MarkFlagRequired("file")
+		settingsCmd.AddCommand(c)
+	}
+	settingsApplyCmd.Flags().BoolVar(&settingsDryRun, "dry-run", false, "Only show the changes that would be applied")
+	rootCmd.AddCommand(settingsCmd)
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/scaffold"
+)
+
+// Special values of --template
+const (
+	templateNone = "none" // don't scaffold anything
+	templateAI   = "ai"   // let the model propose a file manifest
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List the project templates available to init --template",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		result := make([]templateOutcome, 0, len(scaffold.Builtin))
+		for _, t := range scaffold.Builtin {
+			fmt.Printf("%-16s %s\n", t.Name, t.Description)
+			result = append(result, templateOutcome{Name: t.Name, Description: t.Description})
+		}
+		current.Result = result
+	},
+}
+
+// templateOutcome is a built-in template in the JSON report of templates
+type templateOutcome struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// selectTemplate resolves --template: an explicit name, "none", or a match on the stack
+// when empty. It reports false when nothing should be scaffolded.
+func selectTemplate(name, stack string) (scaffold.Template, bool, error) {
+	switch name {
+	case templateNone:
+		return scaffold.Template{}, false, nil
+	case "":
+		t, ok := scaffold.MatchStack(stack)
+		return t, ok, nil
+	}
+	t, err := scaffold.Lookup(name)
+	if err != nil {
+		return scaffold.Template{}, false, err
+	}
+	return t, true, nil
+}
+
+// scaffoldSource is what init scaffolds the project from. It is resolved from
+// --template, --template-dir and --template-repo before anything is created, so a typo
+// fails init without leaving a half-initialised repository behind.
+type scaffoldSource struct {
+	// template is the built-in template to render, when builtin is set
+	template scaffold.Template
+	builtin  bool
+	// ai lets the model propose the files
+	ai bool
+	// local is the --template-dir template. values holds its variables once
+	// checkLocalTemplate has run; those the model answers are asked for at render time.
+	local  *scaffold.LocalTemplate
+	values map[string]string
+}
+
+// resolveScaffold works out what init scaffolds from and checks that the template exists
+func resolveScaffold(stack string) (scaffoldSource, error) {
+	if templateDir != "" {
+		tmpl, err := scaffold.LoadDir(templateDir)
+		if err != nil {
+			return scaffoldSource{}, fmt.Errorf("failed to load template directory: %w", err)
+		}
+		return scaffoldSource{local: tmpl}, nil
+	}
+	if templateArg == templateAI {
+		return scaffoldSource{ai: true}, nil
+	}
+
+	name := templateArg
+	if name == "" && templateRepo != "" {
+		// The template repository already provides the file structure
+		name = templateNone
+	}
+	tmpl, ok, err := selectTemplate(name, stack)
+	if err != nil {
+		return scaffoldSource{}, err
+	}
+	return scaffoldSource{template: tmpl, builtin: ok}, nil
+}
+
+// scaffoldFiles produces the files committed next to the README from the resolved
+// source, along with a short description of where they came from
+func scaffoldFiles(ctx context.Context, source scaffoldSource, vars scaffold.Vars, openaiKey string) ([]github.File, string, error) {
+	switch {
+	case source.local != nil:
+		return renderLocalTemplate(ctx, source, vars, openaiKey)
+	case source.ai:
+		files, err := generateScaffold(ctx, vars.ProjectName, vars.Idea, vars.Stack, openaiKey)
+		return files, "AI-generated", err
+	case !source.builtin:
+		return nil, "", nil
+	}
+	fmt.Printf("🏗️  Scaffolding from the %s template...\n", source.template.Name)
+	files, err := scaffold.Render(source.template, vars)
+	return files, source.template.Name, err
+}
+
+// checkLocalTemplate resolves the --template-dir variables that don't need the model and
+// renders the template once with placeholders for the rest, so a broken template fails
+// init before the repository is created
+func checkLocalTemplate(source *scaffoldSource, vars scaffold.Vars) error {
+	if source.local == nil {
+		return nil
+	}
+	values := map[string]string{}
+	trial := map[string]string{}
+	for _, v := range source.local.Variables {
+		switch v.Source {
+		case scaffold.SourceIdea:
+			values[v.Name] = vars.Idea
+		case scaffold.SourceStack:
+			values[v.Name] = vars.Stack
+		case scaffold.SourceLLM:
+			trial[v.Name] = ""
+			continue
+		default:
+			def, err := v.ExpandDefault(vars)
+			if err != nil {
+				return err
+			}
+			if assumeYes {
+				values[v.Name] = def
+			} else {
+				values[v.Name] = ask(v.Prompt, def)
+			}
+		}
+		trial[v.Name] = values[v.Name]
+	}
+	if _, err := source.local.Render(vars, trial); err != nil {
+		return fmt.Errorf("template directory %s: %w", source.local.Dir, err)
+	}
+	source.values = values
+	return nil
+}
+
+// renderLocalTemplate renders --template-dir, asking the model for the variables it
+// answers
+func renderLocalTemplate(ctx context.Context, source scaffoldSource, vars scaffold.Vars, openaiKey string) ([]github.File, string, error) {
+	tmpl := source.local
+	fmt.Printf("🏗️  Scaffolding from local template %s...\n", tmpl.Name)
+
+	values := map[string]string{}
+	maps.Copy(values, source.values)
+	questions := map[string]string{}
+	for _, v := range tmpl.Variables {
+		if v.Source == scaffold.SourceLLM {
+			questions[v.Name] = v.Prompt
+		}
+	}
+	if len(questions) > 0 {
+		fmt.Printf("🤖 Asking AI for %d template variable(s)...\n", len(questions))
+		llmCtx, cancel := context.WithTimeout(ctx, llmTimeout)
+		answers, err := openai.FillVariables(llmCtx, vars.Idea, vars.Stack, questions, openaiKey)
+		cancel()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to fill template variables: %w", err)
+		}
+		for name := range questions {
+			values[name] = answers[name]
+		}
+	}
+
+	files, err := tmpl.Render(vars, values)
+	return files, tmpl.Name, err
+}
+
+// generateScaffold asks the model for a file manifest, validates it and asks the user
+// to approve it before anything is committed
+func generateScaffold(ctx context.Context, projectName, idea, stack, openaiKey string) ([]github.File, error) {
+	fmt.Println("🏗️  Generating file structure via AI...")
+	llmCtx, cancel := context.WithTimeout(ctx, llmTimeout)
+	manifest, err := openai.GenerateFiles(llmCtx, projectName, idea, stack, openaiKey)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]github.File, len(manifest))
+	for i, f := range manifest {
+		files[i] = github.File{Path: f.Path, Content: []byte(f.Content)}
+	}
+	if err := scaffold.ValidateManifest(files); err != nil {
+		return nil, fmt.Errorf("generated manifest rejected:\n%w", err)
+	}
+
+	fmt.Printf("📦 Proposed %d file(s):\n", len(manifest))
+	for _, f := range manifest {
+		fmt.Printf("   %-40s %6d B  %s\n", f.Path, len(f.Content), f.Purpose)
+	}
+	if !assumeYes && !confirm("Commit these files?") {
+		fmt.Println("⏭️  Skipping generated files")
+		return nil, nil
+	}
+	return files, nil
+}
+
+// workflowFiles renders the GitHub Actions workflows fitting the stack and checks them
+// against --actions-allow before they are committed
+func workflowFiles(vars scaffold.Vars, branch string) ([]github.File, error) {
+	if !generateCI {
+		return nil, nil
+	}
+	family, ok := scaffold.WorkflowFamily(vars.Stack)
+	if !ok {
+		fmt.Println("⏭️  No CI workflow fits the stack, skipping")
+		return nil, nil
+	}
+
+	fmt.Printf("⚙️  Generating %s CI workflows...\n", family)
+	files, err := scaffold.Workflows(family, vars, branch)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := scaffold.ValidateWorkflow(f.Path, f.Content, actionsAllow); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// communityFiles renders the community health bundle when --community is set
+func communityFiles(ctx context.Context, vars scaffold.Vars, token github.TokenSource) ([]github.File, error) {
+	if !withCommunity {
+		return nil, nil
+	}
+	fmt.Println("🤝 Generating community health files...")
+
+	opts := scaffold.CommunityOptions{
+		CopyrightOwner: copyrightOwner,
+		Year:           time.Now().Year(),
+		Contact:        contactEmail,
+	}
+	if opts.CopyrightOwner == "" {
+		opts.CopyrightOwner = vars.Owner
+	}
+	opts.Family, _ = scaffold.WorkflowFamily(vars.Stack)
+
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+
+	if licenseKey != "" && licenseKey != templateNone {
+		license, err := github.GetLicense(ctx, licenseKey, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch license %s: %w", licenseKey, err)
+		}
+		opts.LicenseText = license.Body
+	}
+
+	conduct, err := github.GetCodeOfConduct(ctx, "contributor_covenant", token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch code of conduct: %w", err)
+	}
+	opts.CodeOfConduct = conduct
+
+	return scaffold.Community(vars, opts)
+}
+
+// mergeFiles combines two file sets; files in override replace those in base with the same path
+func mergeFiles(base, override []github.File) []github.File {
+	seen := make(map[string]bool, len(override))
+	for _, f := range override {
+		seen[f.Path] = true
+	}
+	merged := make([]github.File, 0, len(base)+len(override))
+	for _, f := range base {
+		if !seen[f.Path] {
+			merged = append(merged, f)
+		}
+	}
+	return append(merged, override...)
+}
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import "time"
+
+// Timeouts for a single operation, so a stuck endpoint fails the operation instead of
+// hanging the CLI. Ctrl-C cancels any of them sooner.
+const (
+	// apiTimeout bounds one GitHub or OpenAI API call that doesn't generate anything
+	apiTimeout = 30 * time.Second
+	// llmTimeout bounds one LLM completion; large plans and READMEs take minutes
+	llmTimeout = 5 * time.Minute
+	// commitTimeout bounds writing one commit, which takes several API calls or a
+	// clone and push
+	commitTimeout = 5 * time.Minute
+	// batchTimeout bounds an operation made of a handful of API calls, such as
+	// applying repository settings
+	batchTimeout = 2 * time.Minute
+	// credentialTimeout bounds reading or saving secrets, which may run a helper
+	// command such as gh or git credential
+	credentialTimeout = 30 * time.Second
+)
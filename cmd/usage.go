@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+)
+
+var maxCost float64
+
+// meter records the LLM usage of the run
+var meter *openai.Meter
+
+// startMeter records every LLM call of the run, enforcing max_cost when set
+func startMeter() {
+	meter = openai.NewMeter(cfg.Prices, cfg.MaxCost)
+	openai.SetMeter(meter)
+}
+
+// printUsage prints the tokens and estimated cost of the run's LLM calls, if it made any
+func printUsage() {
+	if meter == nil {
+		return
+	}
+	models, total := meter.Summarize()
+	if total.Calls == 0 {
+		return
+	}
+
+	fmt.Printf("\n💰 LLM usage: %s, %d prompt + %d completion tokens, %s\n",
+		formatCalls(total), total.Usage.PromptTokens, total.Usage.CompletionTokens, formatCost(total))
+	if len(models) > 1 {
+		for _, m := range models {
+			fmt.Printf("   %-28s %-22s %9d tokens  %s\n", m.Model, formatCalls(m), m.Usage.TotalTokens, formatCost(m))
+		}
+	}
+	if meter.Budget > 0 {
+		fmt.Printf("   Budget: $%.4f of $%.2f used\n", total.Cost, meter.Budget)
+	}
+}
+
+// llmUsage is the LLM usage of the run in the JSON report
+type llmUsage struct {
+	Calls            int     `json:"calls"`
+	Cached           int     `json:"cached"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	// Priced is false when some model had no price, so the cost is too low
+	Priced bool `json:"priced"`
+}
+
+// usageReport returns the LLM usage of the run, nil if it made no calls
+func usageReport() *llmUsage {
+	if meter == nil {
+		return nil
+	}
+	_, total := meter.Summarize()
+	if total.Calls == 0 {
+		return nil
+	}
+	return &llmUsage{
+		Calls:            total.Calls,
+		Cached:           total.Cached,
+		PromptTokens:     total.Usage.PromptTokens,
+		CompletionTokens: total.Usage.CompletionTokens,
+		CostUSD:          total.Cost,
+		Priced:           total.Priced,
+	}
+}
+
+func formatCalls(s openai.Summary) string {
+	calls := fmt.Sprintf("%d calls", s.Calls)
+	if s.Cached > 0 {
+		calls += fmt.Sprintf(" (%d cached)", s.Cached)
+	}
+	return calls
+}
+
+func formatCost(s openai.Summary) string {
+	cost := fmt.Sprintf("~$%.4f", s.Cost)
+	if !s.Priced {
+		cost += " (some models have no price; add them under prices in the config)"
+	}
+	return cost
+}
@@ -0,0 +1,202 @@
+// Package cache stores LLM responses on disk, addressed by a hash of the request, so
+// repeating a run with the same inputs costs nothing and returns the same result
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is a cached response
+type Entry struct {
+	Key      string `json:"key"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	// Summary is the start of the last prompt message, to recognise the entry by
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+	Content   string    `json:"content"`
+
+	path string // file the entry was read from
+}
+
+// BadEntry is a file in the cache directory that can't be read as an entry
+type BadEntry struct {
+	Path string
+	Err  error
+}
+
+// Cache is a directory of entries, one JSON file per key
+type Cache struct {
+	Dir string
+	// TTL is how long an entry is served after it was written; zero means forever
+	TTL time.Duration
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
+}
+
+// DefaultDir returns the cache directory, aiagent in the user's cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aiagent"), nil
+}
+
+// Key returns the content address of a request: a hash of the provider and the JSON
+// encoding of everything that affects the response, such as the model, messages and
+// parameters
+func Key(provider string, request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(provider+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the entry for key if there is one that hasn't expired
+func (c *Cache) Get(key string) (*Entry, bool) {
+	e, err := c.read(c.path(key))
+	if err != nil || c.Expired(e) {
+		return nil, false
+	}
+	return e, true
+}
+
+// Put stores an entry under its key
+func (c *Cache) Put(e Entry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = c.now()
+	}
+	path := c.path(e.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write then rename, so a concurrent reader never sees a partial entry
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Expired reports whether an entry is older than the TTL
+func (c *Cache) Expired(e *Entry) bool {
+	return c.TTL > 0 && c.now().Sub(e.CreatedAt) > c.TTL
+}
+
+// Lookup finds an entry by its key or a unique prefix of it, expired or not
+func (c *Cache) Lookup(prefix string) (*Entry, error) {
+	entries, _, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var found *Entry
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Key, prefix) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("cache key prefix %q is ambiguous", prefix)
+		}
+		found = e
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no cache entry %q (see 'cache')", prefix)
+	}
+	return found, nil
+}
+
+// List returns every entry, expired or not, newest first. Files that can't be read
+// or parsed are skipped and returned separately, so one corrupt file doesn't hide
+// the rest of the cache.
+func (c *Cache) List() ([]*Entry, []BadEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(c.Dir, "*", "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []*Entry
+	var bad []BadEntry
+	for _, p := range paths {
+		e, err := c.read(p)
+		if err != nil {
+			bad = append(bad, BadEntry{Path: p, Err: err})
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries, bad, nil
+}
+
+// Prune deletes expired and unreadable entries, or every entry when all is set, and
+// returns how many were deleted
+func (c *Cache) Prune(all bool) (int, error) {
+	entries, bad, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+	var paths []string
+	for _, b := range bad {
+		paths = append(paths, b.Path)
+	}
+	for _, e := range entries {
+		if all || c.Expired(e) {
+			paths = append(paths, e.path)
+		}
+	}
+
+	removed := 0
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		// Drop the shard directory once it is empty; failing because it isn't is fine
+		_ = os.Remove(filepath.Dir(path))
+		removed++
+	}
+	return removed, nil
+}
+
+// path shards entries by the first two characters of the key
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+".json")
+}
+
+func (c *Cache) read(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse cache entry %s: %w", path, err)
+	}
+	e.path = path
+	return &e, nil
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneRemovesUnreadableEntries(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	c := &Cache{Dir: t.TempDir(), TTL: 24 * time.Hour, Now: func() time.Time { return now }}
+
+	if err := c.Put(Entry{Key: "aa11", Model: "m", CreatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(Entry{Key: "bb22", Model: "m", CreatedAt: now.Add(-48 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	// A truncated file and one whose key is too short to shard
+	for name, content := range map[string]string{"cc/cc33.json": "{", "dd/d.json": `{"key": ""}`} {
+		path := filepath.Join(c.Dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, bad, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || len(bad) != 1 {
+		t.Fatalf("List = %d entries and %d bad, want 3 and 1", len(entries), len(bad))
+	}
+
+	// The expired, truncated and empty-key entries go; the fresh one stays
+	removed, err := c.Prune(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Errorf("Prune removed %d, want 3", removed)
+	}
+	entries, bad, err = c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Key != "aa11" || len(bad) != 0 {
+		t.Errorf("after Prune: %d entries, %d bad, want only aa11", len(entries), len(bad))
+	}
+}
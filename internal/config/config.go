@@ -0,0 +1,343 @@
+// Package config loads the CLI configuration from defaults, the config file and its
+// profiles, and environment variables
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/credentials"
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/scaffold"
+)
+
+// Config holds the effective settings of a run
+type Config struct {
+	GitHubToken    string `yaml:"github_token,omitempty"`
+	GitHubUsername string `yaml:"github_username,omitempty"`
+	OpenAIAPIKey   string `yaml:"openai_api_key,omitempty"`
+	// GitBackend is how commits are written: api or git
+	GitBackend   string   `yaml:"git_backend,omitempty"`
+	ActionsAllow []string `yaml:"actions_allow,omitempty"`
+	License      string   `yaml:"license,omitempty"`
+	// RepositorySettings are applied to repositories created by init
+	RepositorySettings *github.Settings `yaml:"repository_settings,omitempty"`
+
+	// CredentialStore is where secrets missing from every other layer are read from:
+	// command, git or file. Empty disables the store.
+	CredentialStore string `yaml:"credential_store,omitempty"`
+	// CredentialCommands maps secret names (github, openai) to helper commands
+	CredentialCommands map[string]string `yaml:"credential_commands,omitempty"`
+	// CredentialFile is the encrypted credentials file; defaults to credentials.enc in Dir
+	CredentialFile string `yaml:"credential_file,omitempty"`
+
+	// GitHubApp, when set, is used for every GitHub call instead of GitHubToken
+	GitHubApp *GitHubApp `yaml:"github_app,omitempty"`
+
+	// Prices overrides or extends openai.DefaultPrices, in USD per million tokens
+	Prices map[string]openai.Price `yaml:"prices,omitempty"`
+	// MaxCost aborts a run before an LLM call would take its cost over this many USD.
+	// Zero means no limit.
+	MaxCost float64 `yaml:"max_cost,omitempty"`
+	// CacheTTL is how long cached LLM responses are reused, as a duration such as 24h;
+	// 0 keeps them until pruned
+	CacheTTL string `yaml:"cache_ttl,omitempty"`
+}
+
+// GitHubApp identifies a GitHub App installation to authenticate as. The installation is
+// looked up from github_username when InstallationID is zero.
+type GitHubApp struct {
+	AppID          int64  `yaml:"app_id,omitempty"`
+	InstallationID int64  `yaml:"installation_id,omitempty"`
+	PrivateKeyFile string `yaml:"private_key_file,omitempty"`
+}
+
+// Sources records where each effective value came from, keyed by its YAML field name
+type Sources map[string]string
+
+// file is the layout of config.yaml: top-level values shared by every profile, plus
+// named profiles that override them
+type file struct {
+	Config         `yaml:",inline"`
+	DefaultProfile string            `yaml:"default_profile"`
+	Profiles       map[string]Config `yaml:"profiles"`
+}
+
+// Environment variables read by Load
+const (
+	EnvGitHubToken    = "GITHUB_TOKEN"
+	EnvGitHubUsername = "GITHUB_USERNAME"
+	EnvOpenAIAPIKey   = "OPENAI_API_KEY"
+	EnvGitBackend     = "AIAGENT_GIT_BACKEND"
+	EnvProfile        = "AIAGENT_PROFILE"
+	EnvConfig         = "AIAGENT_CONFIG"
+	EnvCredentials    = "AIAGENT_CREDENTIAL_STORE"
+	EnvAppID          = "AIAGENT_GITHUB_APP_ID"
+	EnvInstallationID = "AIAGENT_GITHUB_APP_INSTALLATION_ID"
+	EnvAppKeyFile     = "AIAGENT_GITHUB_APP_KEY_FILE"
+	EnvMaxCost        = "AIAGENT_MAX_COST"
+	// EnvPassphrase unlocks the encrypted credentials file without prompting
+	EnvPassphrase = "AIAGENT_PASSPHRASE"
+)
+
+// Defaults returns the built-in configuration
+func Defaults() Config {
+	return Config{
+		GitBackend:   "api",
+		ActionsAllow: append([]string(nil), scaffold.DefaultActionsAllowlist...),
+		License:      "mit",
+		CacheTTL:     "168h",
+	}
+}
+
+// Dir returns the configuration directory, $XDG_CONFIG_HOME/aiagent or ~/.config/aiagent
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "aiagent"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aiagent"), nil
+}
+
+// DefaultPath returns the path of config.yaml in Dir
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// CredentialFilePath returns the encrypted credentials file, credential_file or
+// credentials.enc in Dir
+func (c *Config) CredentialFilePath() (string, error) {
+	if c.CredentialFile != "" {
+		return c.CredentialFile, nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials.enc"), nil
+}
+
+// ResolveCredentials fills the secrets no other layer set from the credential store.
+// Secrets the store doesn't hold are left empty.
+func (c *Config) ResolveCredentials(ctx context.Context, store credentials.Store, sources Sources) error {
+	secrets := []struct {
+		field, name string
+		value       *string
+	}{
+		{FieldGitHubToken, credentials.GitHub, &c.GitHubToken},
+		{FieldOpenAIAPIKey, credentials.OpenAI, &c.OpenAIAPIKey},
+	}
+	for _, s := range secrets {
+		if *s.value != "" {
+			continue
+		}
+		secret, err := store.Get(ctx, s.name)
+		if errors.Is(err, credentials.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s from the %s credential store: %w", s.name, store.Backend(), err)
+		}
+		*s.value = secret
+		sources[s.field] = "credential store " + store.Backend()
+	}
+	return nil
+}
+
+// Load builds the configuration from defaults, then the config file (top-level values,
+// then the selected profile), then environment variables. A .env file in the working
+// directory is loaded into the environment if present. path and profile may be empty to
+// use $AIAGENT_CONFIG or DefaultPath, and $AIAGENT_PROFILE or the file's default_profile.
+// Flags are layered on top by the caller.
+func Load(path, profile string) (*Config, Sources, error) {
+	// A missing .env is fine: variables may already be exported
+	_ = godotenv.Load()
+
+	var cfg Config
+	sources := Sources{}
+	cfg.overlay(Defaults(), "default", sources)
+
+	explicit := path != "" || os.Getenv(EnvConfig) != ""
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	f, err := readFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		f = &file{}
+	case err != nil:
+		return nil, nil, err
+	default:
+		cfg.overlay(f.Config, path, sources)
+	}
+
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile == "" {
+		profile = f.DefaultProfile
+	}
+	if profile != "" {
+		p, ok := f.Profiles[profile]
+		if !ok {
+			return nil, nil, fmt.Errorf("profile %q not found in %s", profile, path)
+		}
+		cfg.overlay(p, fmt.Sprintf("%s (profile %s)", path, profile), sources)
+	}
+
+	env, err := fromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.overlay(env, "", sources)
+	return &cfg, sources, nil
+}
+
+func readFile(path string) (*file, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+func fromEnv() (Config, error) {
+	cfg := Config{
+		GitHubToken:     os.Getenv(EnvGitHubToken),
+		GitHubUsername:  os.Getenv(EnvGitHubUsername),
+		OpenAIAPIKey:    os.Getenv(EnvOpenAIAPIKey),
+		GitBackend:      os.Getenv(EnvGitBackend),
+		CredentialStore: os.Getenv(EnvCredentials),
+	}
+
+	var app GitHubApp
+	for _, id := range []struct {
+		name  string
+		value *int64
+	}{{EnvAppID, &app.AppID}, {EnvInstallationID, &app.InstallationID}} {
+		if v := os.Getenv(id.name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return Config{}, fmt.Errorf("$%s must be a number, got %q", id.name, v)
+			}
+			*id.value = n
+		}
+	}
+	if v := os.Getenv(EnvMaxCost); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("$%s must be a number, got %q", EnvMaxCost, v)
+		}
+		cfg.MaxCost = n
+	}
+
+	app.PrivateKeyFile = os.Getenv(EnvAppKeyFile)
+	if app != (GitHubApp{}) {
+		cfg.GitHubApp = &app
+	}
+	return cfg, nil
+}
+
+// envNames maps fields read from the environment to their variable, for Sources
+var envNames = map[string]string{
+	"github_token":     EnvGitHubToken,
+	"github_username":  EnvGitHubUsername,
+	"openai_api_key":   EnvOpenAIAPIKey,
+	"git_backend":      EnvGitBackend,
+	"credential_store": EnvCredentials,
+	"github_app":       EnvAppID + ", " + EnvInstallationID + ", " + EnvAppKeyFile,
+	"max_cost":         EnvMaxCost,
+}
+
+// overlay copies every value set in src over c and records source for it. An empty
+// source means the environment.
+func (c *Config) overlay(src Config, source string, sources Sources) {
+	set := func(field string, ok bool, apply func()) {
+		if !ok {
+			return
+		}
+		apply()
+		if source == "" {
+			sources[field] = "env " + envNames[field]
+		} else {
+			sources[field] = source
+		}
+	}
+	set("github_token", src.GitHubToken != "", func() { c.GitHubToken = src.GitHubToken })
+	set("github_username", src.GitHubUsername != "", func() { c.GitHubUsername = src.GitHubUsername })
+	set("openai_api_key", src.OpenAIAPIKey != "", func() { c.OpenAIAPIKey = src.OpenAIAPIKey })
+	set("git_backend", src.GitBackend != "", func() { c.GitBackend = src.GitBackend })
+	set("actions_allow", len(src.ActionsAllow) > 0, func() { c.ActionsAllow = src.ActionsAllow })
+	set("license", src.License != "", func() { c.License = src.License })
+	set("repository_settings", src.RepositorySettings != nil, func() { c.RepositorySettings = src.RepositorySettings })
+	set("credential_store", src.CredentialStore != "", func() { c.CredentialStore = src.CredentialStore })
+	set("credential_commands", len(src.CredentialCommands) > 0, func() { c.CredentialCommands = src.CredentialCommands })
+	set("credential_file", src.CredentialFile != "", func() { c.CredentialFile = src.CredentialFile })
+	set("github_app", src.GitHubApp != nil, func() { c.GitHubApp = c.GitHubApp.merge(src.GitHubApp) })
+	set("prices", len(src.Prices) > 0, func() { c.Prices = mergePrices(c.Prices, src.Prices) })
+	set("max_cost", src.MaxCost != 0, func() { c.MaxCost = src.MaxCost })
+	set("cache_ttl", src.CacheTTL != "", func() { c.CacheTTL = src.CacheTTL })
+}
+
+// merge returns a copy of a with the fields set in b applied over it, so a profile or
+// the environment can override part of the App settings
+func (a *GitHubApp) merge(b *GitHubApp) *GitHubApp {
+	var out GitHubApp
+	if a != nil {
+		out = *a
+	}
+	if b.AppID != 0 {
+		out.AppID = b.AppID
+	}
+	if b.InstallationID != 0 {
+		out.InstallationID = b.InstallationID
+	}
+	if b.PrivateKeyFile != "" {
+		out.PrivateKeyFile = b.PrivateKeyFile
+	}
+	return &out
+}
+
+// mergePrices returns the prices of a with those of b added or replaced, so a profile can
+// price one model without repeating the others
+func mergePrices(a, b map[string]openai.Price) map[string]openai.Price {
+	out := make(map[string]openai.Price, len(a)+len(b))
+	for model, p := range a {
+		out[model] = p
+	}
+	for model, p := range b {
+		out[model] = p
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/credentials"
+)
+
+// Field names accepted by Require
+const (
+	FieldGitHubToken    = "github_token"
+	FieldGitHubUsername = "github_username"
+	FieldOpenAIAPIKey   = "openai_api_key"
+)
+
+// Require returns an error naming every listed field that has no value
+func (c *Config) Require(fields ...string) error {
+	values := map[string]string{
+		FieldGitHubToken:    c.GitHubToken,
+		FieldGitHubUsername: c.GitHubUsername,
+		FieldOpenAIAPIKey:   c.OpenAIAPIKey,
+	}
+	var missing []string
+	for _, f := range fields {
+		// A GitHub App stands in for the token
+		if f == FieldGitHubToken && c.GitHubApp != nil {
+			continue
+		}
+		if values[f] == "" {
+			missing = append(missing, fmt.Sprintf("%s (or $%s)", f, envNames[f]))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing configuration: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Validate checks the configuration for missing credentials and invalid values,
+// returning every problem found
+func (c *Config) Validate() error {
+	var errs []error
+	if err := c.Require(FieldGitHubToken, FieldGitHubUsername, FieldOpenAIAPIKey); err != nil {
+		errs = append(errs, err)
+	}
+	if c.GitBackend != "api" && c.GitBackend != "git" {
+		errs = append(errs, fmt.Errorf("git_backend must be api or git, got %q", c.GitBackend))
+	}
+	if app := c.GitHubApp; app != nil {
+		if app.AppID == 0 {
+			errs = append(errs, errors.New("github_app: app_id is required"))
+		}
+		if app.PrivateKeyFile == "" {
+			errs = append(errs, errors.New("github_app: private_key_file is required"))
+		}
+		if app.InstallationID == 0 && c.GitHubUsername == "" {
+			errs = append(errs, errors.New("github_app: installation_id or github_username is required to find the installation"))
+		}
+	}
+	switch c.CredentialStore {
+	case "", credentials.BackendCommand, credentials.BackendGit, credentials.BackendFile:
+	default:
+		errs = append(errs, fmt.Errorf("credential_store must be %s, %s or %s, got %q",
+			credentials.BackendCommand, credentials.BackendGit, credentials.BackendFile, c.CredentialStore))
+	}
+	if c.MaxCost < 0 {
+		errs = append(errs, fmt.Errorf("max_cost must not be negative, got %g", c.MaxCost))
+	}
+	if c.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(c.CacheTTL); err != nil || ttl < 0 {
+			errs = append(errs, fmt.Errorf("cache_ttl must be a duration such as 24h, got %q", c.CacheTTL))
+		}
+	}
+	for model, p := range c.Prices {
+		if p.Prompt < 0 || p.Completion < 0 {
+			errs = append(errs, fmt.Errorf("prices: %s must not be negative", model))
+		}
+	}
+	if s := c.RepositorySettings; s != nil {
+		for _, p := range s.BranchProtection {
+			if p.Branch == "" {
+				errs = append(errs, errors.New("repository_settings: branch_protection entry without a branch"))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the configuration safe to print, with secrets masked
+func (c Config) Redacted() Config {
+	c.GitHubToken = redact(c.GitHubToken)
+	c.OpenAIAPIKey = redact(c.OpenAIAPIKey)
+	return c
+}
+
+// redact keeps just enough of a secret to tell which one is configured
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) < 12 {
+		return "****"
+	}
+	return secret[:4] + "****" + secret[len(secret)-4:]
+}
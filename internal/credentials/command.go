@@ -0,0 +1,52 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultCommands are used for secrets without a configured command
+var DefaultCommands = map[string]string{
+	GitHub: "gh auth token",
+}
+
+// CommandStore reads secrets from the output of helper commands such as `gh auth token`.
+// Commands are split on whitespace and run without a shell.
+type CommandStore struct {
+	Commands map[string]string
+}
+
+func (s *CommandStore) Backend() string { return BackendCommand }
+
+// Get runs the secret's command and returns its trimmed output
+func (s *CommandStore) Get(ctx context.Context, name string) (string, error) {
+	command := s.Commands[name]
+	if command == "" {
+		command = DefaultCommands[name]
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", ErrNotFound
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	secret := strings.TrimSpace(stdout.String())
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set is not supported: the helper command owns the secret
+func (s *CommandStore) Set(ctx context.Context, name, secret string) error {
+	return ErrReadOnly
+}
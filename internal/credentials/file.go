@@ -0,0 +1,180 @@
+package credentials
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// fileVersion is the format version written to encrypted credential files
+const fileVersion = 1
+
+// scrypt parameters for deriving the file key from the passphrase
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// FileStore keeps secrets in a local file encrypted with AES-256-GCM under a key
+// derived from a passphrase
+type FileStore struct {
+	Path string
+	// Passphrase is called at most once, the first time the file is read or written.
+	// create is true when the file doesn't exist yet, so a prompt can ask for the new
+	// passphrase twice.
+	Passphrase func(create bool) (string, error)
+
+	passphrase string
+}
+
+// encryptedFile is the on-disk layout of a FileStore
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func (s *FileStore) Backend() string { return BackendFile }
+
+// Get decrypts the file and returns the named secret
+func (s *FileStore) Get(ctx context.Context, name string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[name]
+	if !ok || secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set adds or replaces a secret, re-encrypting the whole file with a fresh salt
+func (s *FileStore) Set(ctx context.Context, name, secret string) error {
+	secrets, err := s.load()
+	if errors.Is(err, ErrNotFound) {
+		secrets = map[string]string{}
+		if err := s.unlock(true); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	secrets[name] = secret
+	return s.save(secrets)
+}
+
+// load decrypts the file; a missing file is ErrNotFound
+func (s *FileStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f encryptedFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.Path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("%s: unsupported credentials file version %d", s.Path, f.Version)
+	}
+
+	if err := s.unlock(false); err != nil {
+		return nil, err
+	}
+	gcm, err := s.cipher(f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: wrong passphrase or corrupted credentials file", s.Path)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", s.Path, err)
+	}
+	return secrets, nil
+}
+
+func (s *FileStore) save(secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	f := encryptedFile{Version: fileVersion, Salt: make([]byte, 16)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	gcm, err := s.cipher(f.Salt)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+	// Write next to the file and rename so a failed write never loses the old secrets
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// unlock asks for the passphrase if it isn't known yet; create says the file is about
+// to be created with it
+func (s *FileStore) unlock(create bool) error {
+	if s.passphrase != "" {
+		return nil
+	}
+	passphrase, err := s.Passphrase(create)
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return errors.New("empty passphrase")
+	}
+	s.passphrase = passphrase
+	return nil
+}
+
+// cipher derives the file key for salt from the unlocked passphrase and returns the
+// AEAD using it
+func (s *FileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
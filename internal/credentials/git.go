@@ -0,0 +1,75 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitStore reads and saves secrets through `git credential`, and so through whatever
+// credential helper git is configured with (OS keychain, libsecret, manager, ...)
+type GitStore struct {
+	// Username narrows the lookup when a host has several accounts
+	Username string
+}
+
+func (s *GitStore) Backend() string { return BackendGit }
+
+// Get asks git's credential helpers for the password of the secret's host
+func (s *GitStore) Get(ctx context.Context, name string) (string, error) {
+	out, err := s.run(ctx, "fill", name, "")
+	if err != nil {
+		// git exits non-zero when no helper has the credential and it can't prompt
+		return "", ErrNotFound
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok && password != "" {
+			return password, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Set stores the secret with git's credential helpers
+func (s *GitStore) Set(ctx context.Context, name, secret string) error {
+	if _, err := s.run(ctx, "approve", name, secret); err != nil {
+		return err
+	}
+	return nil
+}
+
+// run invokes `git credential <action>` with a credential description for the
+// secret's host
+func (s *GitStore) run(ctx context.Context, action, name, secret string) ([]byte, error) {
+	host, ok := hosts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown credential %q", name)
+	}
+
+	var input strings.Builder
+	fmt.Fprintf(&input, "protocol=https\nhost=%s\n", host)
+	if s.Username != "" {
+		fmt.Fprintf(&input, "username=%s\n", s.Username)
+	}
+	if secret != "" {
+		fmt.Fprintf(&input, "password=%s\n", secret)
+	}
+	input.WriteString("\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "credential", action)
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Never fall back to an interactive username/password prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git credential %s: %w: %s", action, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
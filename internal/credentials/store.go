@@ -0,0 +1,71 @@
+// Package credentials keeps the CLI's secrets out of plaintext files by reading them
+// from a helper command, git's credential helpers or an encrypted local file
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Names of the secrets kept in a store
+const (
+	GitHub = "github"
+	OpenAI = "openai"
+)
+
+// Store backends
+const (
+	BackendCommand = "command"
+	BackendGit     = "git"
+	BackendFile    = "file"
+)
+
+// ErrNotFound is returned by Get when the store holds no secret with that name
+var ErrNotFound = errors.New("credential not found")
+
+// ErrReadOnly is returned by Set on stores that can't save secrets
+var ErrReadOnly = errors.New("credential store is read-only")
+
+// Store reads and saves secrets by name. Backends that run helper commands stop them
+// when ctx is done.
+type Store interface {
+	Get(ctx context.Context, name string) (string, error)
+	Set(ctx context.Context, name, secret string) error
+	// Backend returns the backend name, used to report where a secret came from
+	Backend() string
+}
+
+// hosts maps secret names to the host they authenticate against
+var hosts = map[string]string{
+	GitHub: "github.com",
+	OpenAI: "api.openai.com",
+}
+
+// Options configure the store returned by New; each backend uses only its own fields
+type Options struct {
+	// Commands maps secret names to the command printing them, for BackendCommand
+	Commands map[string]string
+	// Username is sent to git's credential helpers, for BackendGit
+	Username string
+	// Path and Passphrase locate and unlock the encrypted file, for BackendFile
+	Path       string
+	Passphrase func(create bool) (string, error)
+}
+
+// New returns the store for a backend
+func New(backend string, opts Options) (Store, error) {
+	switch backend {
+	case BackendCommand:
+		return &CommandStore{Commands: opts.Commands}, nil
+	case BackendGit:
+		return &GitStore{Username: opts.Username}, nil
+	case BackendFile:
+		if opts.Path == "" || opts.Passphrase == nil {
+			return nil, errors.New("file credential store needs a path and a passphrase")
+		}
+		return &FileStore{Path: opts.Path, Passphrase: opts.Passphrase}, nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %q (want %s, %s or %s)", backend, BackendCommand, BackendGit, BackendFile)
+	}
+}
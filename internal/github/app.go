@@ -0,0 +1,203 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const apiURL = "https://api.github.com"
+
+// Installation tokens live for an hour; they are renewed when less than
+// tokenRefreshMargin of that remains so a token never expires mid-request
+const tokenRefreshMargin = 5 * time.Minute
+
+// App JWTs may be valid for at most ten minutes. They are backdated to allow for
+// clock drift between this machine and GitHub.
+const (
+	jwtLifetime = 9 * time.Minute
+	jwtBackdate = 60 * time.Second
+)
+
+// AppTokenSource authenticates as a GitHub App installation. It signs a JWT with the
+// App's private key, exchanges it for an installation token and renews the token
+// before it expires. It is safe for concurrent use.
+type AppTokenSource struct {
+	AppID int64
+	// InstallationID selects the installation; when zero it is looked up from Owner
+	InstallationID int64
+	// Owner is the organization the App is installed on. Installation tokens can only
+	// create repositories in organizations, so personal accounts need a token instead.
+	Owner      string
+	PrivateKey *rsa.PrivateKey
+	// BaseURL is the REST API root, https://api.github.com when empty
+	BaseURL string
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewAppTokenSource returns a token source for an App from its PEM encoded private key,
+// as downloaded from the App's settings page
+func NewAppTokenSource(appID, installationID int64, owner string, privateKeyPEM []byte) (*AppTokenSource, error) {
+	key, err := ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if installationID == 0 && owner == "" {
+		return nil, errors.New("GitHub App needs an installation ID or the owner it is installed on")
+	}
+	return &AppTokenSource{AppID: appID, InstallationID: installationID, Owner: owner, PrivateKey: key}, nil
+}
+
+// ParsePrivateKey decodes an RSA private key in PKCS#1 or PKCS#8 PEM form
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Token returns the current installation token, minting a new one when none is cached
+// or the cached one is about to expire
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(tokenRefreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	jwt, err := s.JWT()
+	if err != nil {
+		return "", err
+	}
+	if s.InstallationID == 0 {
+		if s.InstallationID, err = s.findInstallation(ctx, jwt); err != nil {
+			return "", err
+		}
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.baseURL(), s.InstallationID)
+	if err := s.appRequest(ctx, "POST", url, jwt, &result); err != nil {
+		return "", fmt.Errorf("failed to create installation token: %w", err)
+	}
+	if result.Token == "" {
+		return "", errors.New("failed to create installation token: empty token in response")
+	}
+
+	s.token, s.expires = result.Token, result.ExpiresAt
+	return s.token, nil
+}
+
+// Expiry returns when the cached installation token expires, the zero time before the
+// first call to Token
+func (s *AppTokenSource) Expiry() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expires
+}
+
+// JWT returns a token authenticating as the App itself, signed with RS256
+func (s *AppTokenSource) JWT() (string, error) {
+	now := s.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtBackdate).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(s.AppID, 10),
+	})
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign App JWT: %w", err)
+	}
+	return signingInput + "." + enc.EncodeToString(signature), nil
+}
+
+// findInstallation looks up the App's installation on the Owner organization
+func (s *AppTokenSource) findInstallation(ctx context.Context, jwt string) (int64, error) {
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	url := fmt.Sprintf("%s/orgs/%s/installation", s.baseURL(), s.Owner)
+	if err := s.appRequest(ctx, "GET", url, jwt, &installation); err != nil {
+		if isNotFound(err) {
+			return 0, fmt.Errorf("the App is not installed on an organization named %s; GitHub Apps can only create repositories in organizations, so use a token for a personal account: %w", s.Owner, err)
+		}
+		return 0, fmt.Errorf("failed to find the App installation on %s: %w", s.Owner, err)
+	}
+	return installation.ID, nil
+}
+
+// appRequest sends a request authenticated with the App JWT and decodes the response
+func (s *AppTokenSource) appRequest(ctx context.Context, method, url, jwt string, out interface{}) error {
+	req, _ := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(nil))
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return newAPIError(resp, body)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (s *AppTokenSource) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return apiURL
+}
+
+func (s *AppTokenSource) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,221 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testKey is shared by the tests since RSA key generation is slow
+var testKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// verifyJWT checks a JWT's RS256 signature against key and returns its header and claims
+func verifyJWT(t *testing.T, key *rsa.PrivateKey, jwt string) (header map[string]string, claims map[string]interface{}) {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts, want 3", len(parts))
+	}
+	enc := base64.RawURLEncoding
+	signature, err := enc.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("JWT signature doesn't verify: %v", err)
+	}
+
+	for i, out := range []interface{}{&header, &claims} {
+		data, err := enc.DecodeString(parts[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return header, claims
+}
+
+// fakeGitHub serves the App endpoints: the installation lookup for org "acme" and
+// installation tokens valid for an hour from clock
+type fakeGitHub struct {
+	t     *testing.T
+	clock *time.Time
+	mu    sync.Mutex
+	// minted counts the installation tokens created
+	minted int
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		http.Error(w, `{"message":"Requires authentication"}`, http.StatusUnauthorized)
+		return
+	}
+	verifyJWT(f.t, testKey(), jwt)
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/orgs/acme/installation":
+		fmt.Fprint(w, `{"id": 99}`)
+	case r.Method == "POST" && r.URL.Path == "/app/installations/99/access_tokens":
+		f.mu.Lock()
+		f.minted++
+		n := f.minted
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, n, f.clock.Add(time.Hour).Format(time.RFC3339))
+	default:
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func newTestApp(t *testing.T, installationID int64, owner string) (*AppTokenSource, *fakeGitHub) {
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeGitHub{t: t, clock: &clock}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return &AppTokenSource{
+		AppID:          42,
+		InstallationID: installationID,
+		Owner:          owner,
+		PrivateKey:     testKey(),
+		BaseURL:        server.URL,
+		Now:            func() time.Time { return clock },
+	}, fake
+}
+
+func TestAppJWTClaimsAndSignature(t *testing.T) {
+	app, fake := newTestApp(t, 99, "")
+	jwt, err := app.JWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, claims := verifyJWT(t, testKey(), jwt)
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		t.Errorf("header = %v", header)
+	}
+	now := fake.clock.Unix()
+	if claims["iss"] != "42" {
+		t.Errorf("iss = %v, want \"42\"", claims["iss"])
+	}
+	if iat := int64(claims["iat"].(float64)); iat != now-60 {
+		t.Errorf("iat = %d, want %d", iat, now-60)
+	}
+	// GitHub rejects App JWTs valid for more than ten minutes
+	if exp := int64(claims["exp"].(float64)); exp != now+9*60 {
+		t.Errorf("exp = %d, want %d", exp, now+9*60)
+	}
+}
+
+func TestAppTokenIsCachedAndRenewedBeforeExpiry(t *testing.T) {
+	app, fake := newTestApp(t, 99, "")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		token, err := app.Token(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "ghs_1" {
+			t.Errorf("token = %q, want the cached ghs_1", token)
+		}
+	}
+	if want := fake.clock.Add(time.Hour); !app.Expiry().Equal(want) {
+		t.Errorf("Expiry() = %v, want %v", app.Expiry(), want)
+	}
+
+	// Still well within the hour: reuse the token
+	*fake.clock = fake.clock.Add(50 * time.Minute)
+	if token, _ := app.Token(ctx); token != "ghs_1" {
+		t.Errorf("after 50 minutes token = %q, want ghs_1", token)
+	}
+
+	// Less than the refresh margin left: renew
+	*fake.clock = fake.clock.Add(6 * time.Minute)
+	if token, _ := app.Token(ctx); token != "ghs_2" {
+		t.Errorf("4 minutes before expiry token = %q, want a new ghs_2", token)
+	}
+	if fake.minted != 2 {
+		t.Errorf("minted %d tokens, want 2", fake.minted)
+	}
+}
+
+func TestAppTokenLooksUpOrganizationInstallation(t *testing.T) {
+	app, _ := newTestApp(t, 0, "acme")
+	token, err := app.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghs_1" || app.InstallationID != 99 {
+		t.Errorf("token = %q, installation = %d; want ghs_1 from installation 99", token, app.InstallationID)
+	}
+}
+
+func TestAppTokenReportsErrors(t *testing.T) {
+	// The App isn't installed on the account, which may be a user rather than an org
+	app, _ := newTestApp(t, 0, "octocat")
+	_, err := app.Token(context.Background())
+	if err == nil || !isNotFound(err) || !strings.Contains(err.Error(), "octocat") || !strings.Contains(err.Error(), "organization") {
+		t.Errorf("missing installation: err = %v", err)
+	}
+
+	// GitHub's error body is passed on
+	app, _ = newTestApp(t, 7, "")
+	_, err = app.Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "status 404") || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("unknown installation: err = %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"expires_at": "2024-05-01T13:00:00Z"}`)
+	}))
+	defer server.Close()
+	app.BaseURL = server.URL
+	if _, err := app.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "empty token") {
+		t.Errorf("empty token: err = %v", err)
+	}
+}
+
+func TestParsePrivateKeyFormats(t *testing.T) {
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(testKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, block := range map[string]*pem.Block{
+		"PKCS#1": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testKey())},
+		"PKCS#8": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		key, err := ParsePrivateKey(pem.EncodeToMemory(block))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !key.Equal(testKey()) {
+			t.Errorf("%s: parsed a different key", name)
+		}
+	}
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("parsed a key from garbage")
+	}
+}
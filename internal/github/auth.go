@@ -0,0 +1,80 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RequiredScopes are the classic token scopes the CLI needs: repo to create repositories,
+// issues and pull requests, and workflow to commit CI workflow files
+var RequiredScopes = []string{"repo", "workflow"}
+
+// TokenInfo describes the account a token belongs to and what it may do
+type TokenInfo struct {
+	Login string
+	// Scopes are the OAuth scopes of a classic token. They are nil for fine-grained
+	// tokens, whose permissions GitHub doesn't report.
+	Scopes []string
+}
+
+// GetTokenInfo looks up the user and scopes of a token
+func GetTokenInfo(ctx context.Context, token string) (TokenInfo, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/user", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	if resp.StatusCode >= 300 {
+		return TokenInfo{}, fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to decode user: %w", err)
+	}
+
+	info := TokenInfo{Login: user.Login}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		info.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	return info, nil
+}
+
+// MissingScopes returns the required scopes the token lacks. It is always empty for
+// fine-grained tokens, since their permissions can't be checked up front.
+func (i TokenInfo) MissingScopes(required []string) []string {
+	if i.Scopes == nil {
+		return nil
+	}
+	have := map[string]bool{}
+	for _, s := range i.Scopes {
+		have[s] = true
+	}
+	var missing []string
+	for _, s := range required {
+		if !have[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
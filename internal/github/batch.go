@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+	. "github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+// issueAttempts is how many times a rate-limited issue request is sent
+const issueAttempts = 5
+
+// IssueResult is the outcome of filing one task
+type IssueResult struct {
+	Task Task
+	// Issue is set once the issue exists, even if filling it in failed afterwards
+	Issue *Issue
+	Err   error
+}
+
+// CreateIssues files tasks as issues numbered in task order, using up to workers
+// concurrent requests. GitHub numbers issues in the order it creates them, so each
+// number is reserved first by creating the issues one at a time with just their title;
+// bodies and labels are then filled in concurrently. All requests share one Backoff, so
+// a rate limit pauses every worker. The results are in task order.
+func CreateIssues(ctx context.Context, owner, repo string, token TokenSource, tasks []Task, workers int) []IssueResult {
+	results := make([]IssueResult, len(tasks))
+	backoff := &Backoff{MaxAttempts: issueAttempts}
+
+	for i, task := range tasks {
+		results[i].Task = task
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Err = backoff.Do(ctx, func() error {
+			issue, err := postIssue(ctx, owner, repo, token, map[string]interface{}{"title": task.Title})
+			results[i].Issue = issue
+			return err
+		})
+		if results[i].Issue != nil {
+			logging.FromContext(ctx).DebugContext(ctx, "reserved issue number", "number", results[i].Issue.Number, "task", task.Title)
+		}
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := &results[i]
+				r.Err = backoff.Do(ctx, func() error {
+					issue, err := UpdateIssue(ctx, owner, repo, r.Issue.Number, token, r.Task)
+					if err == nil {
+						r.Issue = issue
+					}
+					return err
+				})
+				if r.Err != nil {
+					logging.FromContext(ctx).DebugContext(ctx, "failed to fill in issue", "number", r.Issue.Number, "error", r.Err)
+				}
+			}
+		}()
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
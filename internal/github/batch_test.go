@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+func TestCreateIssuesReservesNumbersInOrder(t *testing.T) {
+	var mu sync.Mutex
+	created := map[int]string{}
+	patched := map[int]map[string]interface{}{}
+	limited := false
+	serveAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var fields map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&fields)
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repos/me/app/issues":
+			if _, ok := fields["body"]; ok {
+				t.Errorf("issue %q was created with its body; only the title reserves a number", fields["title"])
+			}
+			n := len(created) + 1
+			created[n] = fields["title"].(string)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"number": %d, "title": %q}`, n, fields["title"])
+		case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/repos/me/app/issues/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/repos/me/app/issues/"))
+			// The first fill-in is rate limited and must be retried
+			if !limited {
+				limited = true
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, `{"message":"You have exceeded a secondary rate limit"}`, http.StatusForbidden)
+				return
+			}
+			if n == 3 {
+				http.Error(w, `{"message":"Validation Failed"}`, http.StatusUnprocessableEntity)
+				return
+			}
+			patched[n] = fields
+			fmt.Fprintf(w, `{"number": %d, "title": %q, "body": %q}`, n, fields["title"], fields["body"])
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	}))
+
+	var list []Task
+	for i := 1; i <= 5; i++ {
+		list = append(list, Task{Title: fmt.Sprintf("Task %d", i), Body: fmt.Sprintf("Body %d", i), Labels: []string{"feature"}})
+	}
+	results := CreateIssues(context.Background(), "me", "app", StaticToken("t"), list, 3)
+
+	for i, r := range results {
+		n := i + 1
+		if r.Task.Title != list[i].Title || r.Issue == nil || r.Issue.Number != n {
+			t.Errorf("result %d = %+v, want issue #%d for %q", i, r, n, list[i].Title)
+			continue
+		}
+		if created[n] != list[i].Title {
+			t.Errorf("issue #%d was reserved for %q, want %q", n, created[n], list[i].Title)
+		}
+		if n == 3 {
+			if r.Err == nil {
+				t.Error("the failed fill-in of #3 wasn't reported")
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("issue #%d: %v", n, r.Err)
+		}
+		if body, _ := patched[n]["body"].(string); !strings.HasPrefix(body, list[i].Body) {
+			t.Errorf("issue #%d body = %q", n, body)
+		}
+		if labels, _ := patched[n]["labels"].([]interface{}); len(labels) != 1 || labels[0] != "feature" {
+			t.Errorf("issue #%d labels = %v", n, patched[n]["labels"])
+		}
+	}
+}
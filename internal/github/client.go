@@ -2,10 +2,14 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
+	"strings"
 
 	. "github.com/TheAlonso95/ai-dev-agent/internal/tasks"
 )
@@ -20,20 +24,87 @@ type Issue struct {
 	Number  int    `json:"number"`
 	Title   string `json:"title"`
 	Body    string `json:"body"`
+	State   string `json:"state"`
 	HTMLURL string `json:"html_url"`
 }
 
+// Git file modes accepted in File.Mode
+const (
+	ModeRegular    = "100644"
+	ModeExecutable = "100755"
+	ModeSymlink    = "120000"
+)
+
+// File is a change to a single path in a commit
 type File struct {
-	Path    string
-	Content string
+	Path string
+	// Content holds the raw file bytes, or the link target for symlinks
+	Content []byte
+	// Mode is one of the Mode constants; empty means ModeRegular
+	Mode string
+	// Delete removes Path from the tree instead of writing it
+	Delete bool
+}
+
+func (f File) mode() string {
+	if f.Mode == "" {
+		return ModeRegular
+	}
+	return f.Mode
 }
 
-func CreateRepo(repoName string, token string) error {
+// Check rejects a file whose path, or symlink target, leaves the repository
+func (f File) Check() error {
+	if err := CheckPath(f.Path); err != nil {
+		return err
+	}
+	if f.Delete || f.mode() != ModeSymlink {
+		return nil
+	}
+	target := string(f.Content)
+	if target == "" || path.IsAbs(target) || strings.Contains(target, "\\") {
+		return fmt.Errorf("%s: symlink target %q must be a relative path", f.Path, target)
+	}
+	resolved := path.Join(path.Dir(path.Clean(f.Path)), target)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return fmt.Errorf("%s: symlink target %q leaves the repository", f.Path, target)
+	}
+	return nil
+}
+
+// CheckPath rejects empty and absolute paths and paths that escape the repository root
+func CheckPath(p string) error {
+	if p == "" {
+		return errors.New("empty path")
+	}
+	if path.IsAbs(p) || strings.Contains(p, "\\") {
+		return fmt.Errorf("%s: path must be relative and use forward slashes", p)
+	}
+	clean := path.Clean(p)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("%s: path escapes the repository", p)
+	}
+	if clean == ".git" || strings.HasPrefix(clean, ".git/") {
+		return fmt.Errorf("%s: path is inside .git", p)
+	}
+	return nil
+}
+
+func CreateRepo(ctx context.Context, repoName string, token TokenSource) error {
 	repo := Repo{Name: repoName, Private: false, AutoInit: true}
 	jsonData, _ := json.Marshal(repo)
 
-	req, _ := http.NewRequest("POST", "https://api.github.com/user/repos", bytes.NewBuffer(jsonData))
-	req.Header.Set("Authorization", "Bearer "+token)
+	url := "https://api.github.com/user/repos"
+	// Installation tokens act as the App rather than a user, so repositories are
+	// created in the organization the App is installed on
+	if app, ok := token.(*AppTokenSource); ok {
+		url = fmt.Sprintf("https://api.github.com/orgs/%s/repos", app.Owner)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err := authorize(req, token); err != nil {
+		return err
+	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 
 	resp, err := http.DefaultClient.Do(req)
@@ -44,13 +115,17 @@ func CreateRepo(repoName string, token string) error {
 
 	if resp.StatusCode != 201 {
 		body, _ := io.ReadAll(resp.Body)
+		if app, ok := token.(*AppTokenSource); ok && resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("failed to create repo: %s is not an organization the App is installed on; GitHub Apps can only create repositories in organizations, so use a token for a personal account: %s", app.Owner, body)
+		}
 		return fmt.Errorf("failed to create repo: %s", body)
 	}
 
 	return nil
 }
 
-func CreateIssue(owner, repo, token string, task Task) error {
+// IssueBody renders a task as the markdown body used for its GitHub issue
+func IssueBody(task Task) string {
 	// Format acceptance criteria into markdown
 	acSection := ""
 	if len(task.AcceptanceCriteria) > 0 {
@@ -60,42 +135,52 @@ func CreateIssue(owner, repo, token string, task Task) error {
 		}
 	}
 
-	fullBody := fmt.Sprintf("%s\n\n%s", task.Body, acSection)
+	return fmt.Sprintf("%s\n\n%s", task.Body, acSection)
+}
 
-	issue := map[string]interface{}{
+// UpdateIssue replaces the title, body and labels of an issue with those of a task
+func UpdateIssue(ctx context.Context, owner, repo string, number int, token TokenSource, task Task) (*Issue, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", owner, repo, number)
+	data, _ := json.Marshal(map[string]interface{}{
 		"title":  task.Title,
-		"body":   fullBody,
-		"labels": task.Labels,
+		"body":   IssueBody(task),
+		"labels": nonNil(task.Labels),
+	})
+	resp, err := doPatch(ctx, url, data, token)
+	if err != nil {
+		return nil, err
 	}
-	jsonData, _ := json.Marshal(issue)
+	return decodeIssue(resp)
+}
 
+func postIssue(ctx context.Context, owner, repo string, token TokenSource, fields map[string]interface{}) (*Issue, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues", owner, repo)
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-
-	resp, err := http.DefaultClient.Do(req)
+	data, _ := json.Marshal(fields)
+	resp, err := doPost(ctx, url, data, token)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("issue creation failed: %w", err)
 	}
-	defer resp.Body.Close()
+	return decodeIssue(resp)
+}
 
-	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("issue creation failed: %s", body)
+func decodeIssue(resp []byte) (*Issue, error) {
+	var issue Issue
+	if err := json.Unmarshal(resp, &issue); err != nil {
+		return nil, fmt.Errorf("failed to decode issue: %w", err)
 	}
-
-	return nil
+	return &issue, nil
 }
 
-func FetchIssue(owner, repo string, issueNumber int, token string) (*Issue, error) {
+func FetchIssue(ctx context.Context, owner, repo string, issueNumber int, token TokenSource) (*Issue, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", owner, repo, issueNumber)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
+	if err := authorize(req, token); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 
 	resp, err := http.DefaultClient.Do(req)
@@ -0,0 +1,234 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+)
+
+// Committer writes a set of files to a branch as a single commit and returns its SHA.
+// If branch doesn't exist it is created from base.
+type Committer interface {
+	Commit(ctx context.Context, branch, base, message string, files []File) (string, error)
+}
+
+// APICommitter commits through the Git Data REST API (blobs, trees, commits and refs)
+type APICommitter struct {
+	Owner string
+	Repo  string
+	Token TokenSource
+}
+
+// Commit implements Committer
+func (c *APICommitter) Commit(ctx context.Context, branch, base, message string, files []File) (string, error) {
+	return CommitToBranch(ctx, c.Owner, c.Repo, branch, base, message, files, c.Token)
+}
+
+// GitCommitter commits with the local git binary: it clones the remote into a temporary
+// working copy, writes the files, commits and pushes.
+type GitCommitter struct {
+	// RemoteURL is anything git can clone, including a path to a local bare repository
+	RemoteURL   string
+	AuthorName  string
+	AuthorEmail string
+	// Token, if set, is sent as HTTP basic auth to the remote
+	Token TokenSource
+}
+
+// NewGitCommitter returns a GitCommitter for a GitHub repository, authoring commits as owner
+func NewGitCommitter(owner, repo string, token TokenSource) *GitCommitter {
+	return &GitCommitter{
+		RemoteURL:   fmt.Sprintf("https://github.com/%s/%s.git", owner, repo),
+		AuthorName:  owner,
+		AuthorEmail: owner + "@users.noreply.github.com",
+		Token:       token,
+	}
+}
+
+// Commit implements Committer
+func (c *GitCommitter) Commit(ctx context.Context, branch, base, message string, files []File) (string, error) {
+	if err := checkFiles(files); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "aiagent-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create workspace: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := c.git(ctx, dir, "clone", "--quiet", "--no-checkout", c.RemoteURL, "."); err != nil {
+		return "", err
+	}
+
+	switch {
+	case c.hasRemoteBranch(ctx, dir, branch):
+		_, err = c.git(ctx, dir, "checkout", "--quiet", "-B", branch, "origin/"+branch)
+	case c.hasRemoteBranch(ctx, dir, base):
+		_, err = c.git(ctx, dir, "checkout", "--quiet", "-B", branch, "origin/"+base)
+	default:
+		// Empty repository: start the branch without history
+		_, err = c.git(ctx, dir, "checkout", "--quiet", "--orphan", branch)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		if err := writeWorkingFile(dir, file); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	if _, err := c.git(ctx, dir, "add", "--all"); err != nil {
+		return "", err
+	}
+	if _, err := c.git(ctx, dir,
+		"-c", "user.name="+c.AuthorName,
+		"-c", "user.email="+c.AuthorEmail,
+		"commit", "--quiet", "--allow-empty", "-m", message); err != nil {
+		return "", err
+	}
+	if _, err := c.git(ctx, dir, "push", "--quiet", "origin", "HEAD:refs/heads/"+branch); err != nil {
+		return "", err
+	}
+
+	sha, err := c.git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// checkFiles rejects a change set with any file that leaves the repository, before
+// anything is written
+func checkFiles(files []File) error {
+	for _, f := range files {
+		if err := f.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeWorkingFile applies a File to the working copy in dir, honouring its mode. It
+// only ever removes the file itself, never a directory tree.
+func writeWorkingFile(dir string, file File) error {
+	if err := file.Check(); err != nil {
+		return err
+	}
+	rel := filepath.FromSlash(path.Clean(file.Path))
+	if err := checkParents(dir, rel); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, rel)
+
+	if file.Delete {
+		if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	// Replace whatever is there, since a symlink can't be overwritten in place
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	switch file.mode() {
+	case ModeSymlink:
+		return os.Symlink(string(file.Content), target)
+	case ModeExecutable:
+		return os.WriteFile(target, file.Content, 0o755)
+	default:
+		return os.WriteFile(target, file.Content, 0o644)
+	}
+}
+
+// checkParents rejects a path whose parent directories in the working copy include a
+// symlink, which could lead writes outside dir
+func checkParents(dir, rel string) error {
+	parts := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	current := dir
+	for _, part := range parts {
+		if part == "." {
+			break
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%s: parent directory %s is a symlink", filepath.ToSlash(rel), part)
+		}
+	}
+	return nil
+}
+
+func (c *GitCommitter) hasRemoteBranch(ctx context.Context, dir, branch string) bool {
+	_, err := c.git(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch)
+	return err == nil
+}
+
+// git runs a git subcommand in dir and returns its trimmed stdout
+func (c *GitCommitter) git(ctx context.Context, dir string, args ...string) (string, error) {
+	// Never prompt for credentials; fail instead
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if c.Token != nil {
+		token, err := c.Token.Token(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get GitHub token: %w", err)
+		}
+		if token != "" {
+			auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+			env = withGitConfig(env, "http.extraHeader", "Authorization: Basic "+auth)
+		}
+	}
+
+	logging.FromContext(ctx).DebugContext(ctx, "running git", "args", strings.Join(args, " "), "dir", dir)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// withGitConfig adds a configuration entry to a git environment through
+// GIT_CONFIG_COUNT, so secrets such as the authorization header stay out of the command
+// line, where any local user could read them. Entries already in env are kept.
+func withGitConfig(env []string, key, value string) []string {
+	count := 0
+	for _, kv := range env {
+		if n, ok := strings.CutPrefix(kv, "GIT_CONFIG_COUNT="); ok {
+			count, _ = strconv.Atoi(n)
+		}
+	}
+	return append(env,
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, value),
+	)
+}
@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBareRemote creates an empty bare repository to push to
+func newBareRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+	return dir
+}
+
+// remoteGit runs git against the bare remote and returns its trimmed output
+func remoteGit(t *testing.T, remote string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"--git-dir", remote}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitCommitterPushesToBareRepository(t *testing.T) {
+	remote := newBareRemote(t)
+	c := &GitCommitter{RemoteURL: remote, AuthorName: "octocat", AuthorEmail: "octocat@example.com"}
+	ctx := context.Background()
+
+	// The first commit into the empty repository starts main without history
+	first, err := c.Commit(ctx, "main", "main", "Initial commit", []File{
+		{Path: "README.md", Content: []byte("# demo\n")},
+		{Path: "scripts/run.sh", Content: []byte("#!/bin/sh\n"), Mode: ModeExecutable},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := remoteGit(t, remote, "rev-parse", "refs/heads/main"); got != first {
+		t.Errorf("main = %s, want %s", got, first)
+	}
+	if got := remoteGit(t, remote, "ls-tree", "main", "scripts/run.sh"); !strings.HasPrefix(got, ModeExecutable+" ") {
+		t.Errorf("scripts/run.sh tree entry = %q, want mode %s", got, ModeExecutable)
+	}
+
+	// A new branch starts from base and can delete files
+	second, err := c.Commit(ctx, "feature", "main", "Drop the script", []File{
+		{Path: "scripts/run.sh", Delete: true},
+		{Path: "main.go", Content: []byte("package main\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := remoteGit(t, remote, "rev-parse", "feature^"); got != first {
+		t.Errorf("feature's parent = %s, want %s", got, first)
+	}
+	if got := remoteGit(t, remote, "rev-parse", "refs/heads/feature"); got != second {
+		t.Errorf("feature = %s, want %s", got, second)
+	}
+	if got := remoteGit(t, remote, "ls-tree", "-r", "--name-only", "feature"); got != "README.md\nmain.go" {
+		t.Errorf("feature files = %q", got)
+	}
+	if got := remoteGit(t, remote, "log", "-1", "--format=%an <%ae> %s", "feature"); got != "octocat <octocat@example.com> Drop the script" {
+		t.Errorf("feature commit = %q", got)
+	}
+}
+
+func TestGitCommitterKeepsTokenOffCommandLine(t *testing.T) {
+	remote := newBareRemote(t)
+
+	// A wrapper around git records the arguments it is run with
+	bin := t.TempDir()
+	argsLog := filepath.Join(bin, "args.log")
+	realGit, _ := exec.LookPath("git")
+	wrapper := "#!/bin/sh\necho \"$@\" >> " + argsLog + "\nexec " + realGit + " \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(wrapper), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c := &GitCommitter{RemoteURL: remote, AuthorName: "octocat", AuthorEmail: "octocat@example.com", Token: StaticToken("ghs_secret")}
+	if _, err := c.Commit(context.Background(), "main", "main", "Initial commit", []File{{Path: "README.md", Content: []byte("hi\n")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(args), "extraHeader") || strings.Contains(string(args), "Authorization") {
+		t.Errorf("git was run with the authorization header on its command line:\n%s", args)
+	}
+}
+
+func TestWithGitConfigAppendsToExistingEntries(t *testing.T) {
+	env := withGitConfig([]string{"HOME=/tmp", "GIT_CONFIG_COUNT=2"}, "http.extraHeader", "Authorization: Basic abc")
+	want := []string{"HOME=/tmp", "GIT_CONFIG_COUNT=2", "GIT_CONFIG_COUNT=3", "GIT_CONFIG_KEY_2=http.extraHeader", "GIT_CONFIG_VALUE_2=Authorization: Basic abc"}
+	if strings.Join(env, "\n") != strings.Join(want, "\n") {
+		t.Errorf("env = %q, want %q", env, want)
+	}
+}
+
+func TestWriteWorkingFileStaysInsideWorkspace(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "work")
+	outside := filepath.Join(root, "outside.txt")
+	for _, p := range []string{filepath.Join(dir, "src", "main.go"), outside} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("keep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(root, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []File{
+		{Path: "", Delete: true},
+		{Path: ".", Delete: true},
+		{Path: "../outside.txt", Delete: true},
+		{Path: "/etc/passwd", Content: []byte("x")},
+		{Path: "link", Mode: ModeSymlink, Content: []byte("../../outside.txt")},
+		{Path: "link", Mode: ModeSymlink, Content: []byte("/etc/passwd")},
+		{Path: "escape/outside.txt", Content: []byte("x")},
+		{Path: "src", Delete: true},
+	} {
+		if err := writeWorkingFile(dir, f); err == nil {
+			t.Errorf("writeWorkingFile(%+v) succeeded, want an error", f)
+		}
+	}
+
+	for _, p := range []string{filepath.Join(dir, "src", "main.go"), outside} {
+		if data, err := os.ReadFile(p); err != nil || string(data) != "keep" {
+			t.Errorf("%s was changed: %q, %v", p, data, err)
+		}
+	}
+
+	// Links that stay in the tree are fine
+	if err := writeWorkingFile(dir, File{Path: "docs/main.go", Mode: ModeSymlink, Content: []byte("../src/main.go")}); err != nil {
+		t.Error(err)
+	}
+}
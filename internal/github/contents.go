@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Repository holds the repository metadata the agent needs
+type Repository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	HTMLURL       string `json:"html_url"`
+}
+
+// TreeEntry is a single file or directory in a git tree
+type TreeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+	Size int    `json:"size"`
+}
+
+// GetRepository fetches repository metadata such as the default branch
+func GetRepository(ctx context.Context, owner, repo string, token TokenSource) (*Repository, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result Repository
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode repository: %w", err)
+	}
+	return &result, nil
+}
+
+// ErrTreeTruncated is returned with the entries of a tree too large for GitHub to list
+// in full
+var ErrTreeTruncated = errors.New("repository tree is too large to list in full")
+
+// ListTree returns every entry reachable from ref, recursively. For very large trees
+// GitHub returns only part of them; ListTree then returns that part with
+// ErrTreeTruncated.
+func ListTree(ctx context.Context, owner, repo, ref string, token TokenSource) ([]TreeEntry, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, ref)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Tree      []TreeEntry `json:"tree"`
+		Truncated bool        `json:"truncated"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode tree: %w", err)
+	}
+	if result.Truncated {
+		return result.Tree, ErrTreeTruncated
+	}
+	return result.Tree, nil
+}
+
+// GetBlob downloads the raw contents of a blob
+func GetBlob(ctx context.Context, owner, repo, sha string, token TokenSource) ([]byte, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/blobs/%s", owner, repo, sha)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode blob: %w", err)
+	}
+
+	if result.Encoding != "base64" {
+		return []byte(result.Content), nil
+	}
+	// GitHub wraps base64 content at 60 columns
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(result.Content, "\n", ""))
+}
@@ -1,149 +1,116 @@
 package github
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"net/http"
 	"strings"
+	"unicode/utf8"
 )
 
-// InitializeRepoWithReadme creates the first commit for an empty repository with a README file
-func InitializeRepoWithReadme(repo string, file File, token string) error {
-	owner := os.Getenv("GITHUB_USERNAME")
+// maxBlobSize is the largest file GitHub accepts in a blob
+const maxBlobSize = 100 << 20
 
-	// For empty repositories, we need to create a commit without a parent
+// refAttempts is how many times a commit is rebuilt when the branch moves under it
+const refAttempts = 3
 
-	// 1. Create a blob for the README file
-	blobSHA, err := createBlob(owner, repo, file, token)
-	if err != nil {
-		fmt.Printf("Error creating blob: %v\n", err)
-		return err
-	}
-
-	// 2. Create a tree without a base tree
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees", owner, repo)
-	body := map[string]interface{}{
-		"tree": []map[string]string{
-			{
-				"path": file.Path,
-				"mode": "100644",
-				"type": "blob",
-				"sha":  blobSHA,
-			},
-		},
-	}
-	data, _ := json.Marshal(body)
-	resp, err := doPost(url, data, token)
-	if err != nil {
-		fmt.Printf("Error creating initial tree: %v\n", err)
-		return err
-	}
-
-	var treeResult struct {
-		SHA string `json:"sha"`
-	}
-	if err := json.Unmarshal(resp, &treeResult); err != nil {
-		fmt.Printf("Error parsing tree response: %v\n", err)
-		return err
-	}
-
-	// 3. Create a commit without a parent
-	commitURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/commits", owner, repo)
-	commitBody := map[string]interface{}{
-		"message": fmt.Sprintf("Initial commit: Add %s", file.Path),
-		"tree":    treeResult.SHA,
-		// No parents for initial commit
-	}
-	commitData, _ := json.Marshal(commitBody)
-	commitResp, err := doPost(commitURL, commitData, token)
-	if err != nil {
-		fmt.Printf("Error creating initial commit: %v\n", err)
-		return err
-	}
-
-	var commitResult struct {
-		SHA string `json:"sha"`
-	}
-	if err := json.Unmarshal(commitResp, &commitResult); err != nil {
-		fmt.Printf("Error parsing commit response: %v\n", err)
-		return err
-	}
-
-	// 4. Create the main branch reference
-	refURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs", owner, repo)
-	refBody := map[string]interface{}{
-		"ref": "refs/heads/main",
-		"sha": commitResult.SHA,
+// CommitToBranch writes all files as a single commit on top of branch, creating the
+// branch from base first if it doesn't exist yet. In an empty repository the commit
+// becomes the root commit of branch. The branch is only fast-forwarded: if it moves
+// while the commit is built, the commit is rebuilt on the new head. It returns the new
+// commit SHA.
+func CommitToBranch(ctx context.Context, owner, repo, branch, base, message string, files []File, token TokenSource) (string, error) {
+	if err := checkFiles(files); err != nil {
+		return "", err
 	}
-	refData, _ := json.Marshal(refBody)
-	_, err = doPost(refURL, refData, token)
-	if err != nil {
-		fmt.Printf("Error creating main branch reference: %v\n", err)
-		return err
+	parentSHA, baseTreeSHA, err := getBaseCommitAndTree(ctx, owner, repo, branch, token)
+	if err != nil && !isNotFound(err) && !isEmptyRepoError(err) {
+		return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
 	}
-
-	return nil
-}
-
-// CreateBranchAndCommit creates a commit with the given files and pushes it to the main branch
-func CreateBranchAndCommit(repo string, files []File, token string) error {
-	owner := os.Getenv("GITHUB_USERNAME")
-
-	// Check if the repository is empty
-	_, _, err := getBaseCommitAndTree(owner, repo, token)
-	if err != nil {
-		// If we get an error that the repo is empty and we have at least one file, try initializing
-		if len(files) > 0 && strings.Contains(err.Error(), "Git Repository is empty") {
-			fmt.Println("Empty repository detected. Using initialization process...")
-			return InitializeRepoWithReadme(repo, files[0], token)
+	branchExists := err == nil
+	if !branchExists {
+		parentSHA, baseTreeSHA, err = getBaseCommitAndTree(ctx, owner, repo, base, token)
+		if err != nil && !isEmptyRepoError(err) {
+			return "", fmt.Errorf("failed to read base branch %s: %w", base, err)
 		}
-		fmt.Printf("Error getting base commit and tree: %v\n", err)
-		return err
 	}
 
-	// If repository already has commits, proceed with normal process
-	for _, file := range files {
-		blobSHA, err := createBlob(owner, repo, file, token)
+	blobSHAs := make([]string, len(files))
+	for i, file := range files {
+		if file.Delete {
+			continue
+		}
+		blobSHAs[i], err = createBlob(ctx, owner, repo, file, token)
 		if err != nil {
-			fmt.Printf("Error creating blob: %v\n", err)
-			return err
+			return "", fmt.Errorf("failed to create blob for %s: %w", file.Path, err)
 		}
+	}
 
-		baseCommitSHA, baseTreeSHA, err := getBaseCommitAndTree(owner, repo, token)
+	for attempt := 1; ; attempt++ {
+		treeSHA, err := createTree(ctx, owner, repo, files, blobSHAs, baseTreeSHA, token)
 		if err != nil {
-			fmt.Printf("Error getting base commit and tree: %v\n", err)
-			return err
+			return "", fmt.Errorf("failed to create tree: %w", err)
 		}
 
-		treeSHA, err := createTree(owner, repo, file, blobSHA, baseTreeSHA, token)
+		commitSHA, err := createCommit(ctx, owner, repo, message, treeSHA, parentSHA, token)
 		if err != nil {
-			fmt.Printf("Error creating tree: %v\n", err)
-			return err
+			return "", fmt.Errorf("failed to create commit: %w", err)
 		}
 
-		commitSHA, err := createCommit(owner, repo, file, treeSHA, baseCommitSHA, token)
-		if err != nil {
-			fmt.Printf("Error creating commit: %v\n", err)
-			return err
+		if branchExists {
+			err = updateRef(ctx, owner, repo, branch, commitSHA, token)
+		} else {
+			err = createRef(ctx, owner, repo, branch, commitSHA, token)
+		}
+		if err == nil {
+			return commitSHA, nil
+		}
+		if !isRefConflict(err) || attempt == refAttempts {
+			return "", fmt.Errorf("failed to update branch %s: %w", branch, err)
 		}
 
-		err = updateRef(owner, repo, commitSHA, token)
+		// Someone else pushed to (or created) the branch; the blobs still apply, so
+		// only the tree and commit are rebuilt on top of the new head
+		parentSHA, baseTreeSHA, err = getBaseCommitAndTree(ctx, owner, repo, branch, token)
 		if err != nil {
-			fmt.Printf("Error updating reference: %v\n", err)
-			return err
+			return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
 		}
+		branchExists = true
 	}
-	return nil
 }
 
-func createBlob(owner, repo string, file File, token string) (string, error) {
+func isEmptyRepoError(err error) bool {
+	return strings.Contains(err.Error(), "Git Repository is empty")
+}
+
+// isRefConflict reports whether GitHub refused to move a ref because it isn't a fast
+// forward, or to create one that already exists
+func isRefConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity
+}
+
+func createBlob(ctx context.Context, owner, repo string, file File, token TokenSource) (string, error) {
+	if len(file.Content) > maxBlobSize {
+		return "", fmt.Errorf("%s is %d bytes, larger than the %d byte GitHub limit", file.Path, len(file.Content), maxBlobSize)
+	}
+
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/blobs", owner, repo)
 	body := map[string]string{
-		"content":  file.Content,
+		"content":  string(file.Content),
 		"encoding": "utf-8",
 	}
+	// Anything that isn't valid UTF-8 (images, fonts, archives) would be mangled
+	// by the utf-8 encoding, so send it as base64
+	if !utf8.Valid(file.Content) {
+		body["content"] = base64.StdEncoding.EncodeToString(file.Content)
+		body["encoding"] = "base64"
+	}
 	data, _ := json.Marshal(body)
-	resp, err := doPost(url, data, token)
+	resp, err := doPost(ctx, url, data, token)
 	if err != nil {
 		return "", err
 	}
@@ -154,9 +121,9 @@ func createBlob(owner, repo string, file File, token string) (string, error) {
 	return result.SHA, nil
 }
 
-func getBaseCommitAndTree(owner, repo, token string) (string, string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/heads/main", owner, repo)
-	resp, err := doGet(url, token)
+func getBaseCommitAndTree(ctx context.Context, owner, repo, branch string, token TokenSource) (string, string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/heads/%s", owner, repo, branch)
+	resp, err := doGet(ctx, url, token)
 	if err != nil {
 		return "", "", err
 	}
@@ -174,7 +141,7 @@ func getBaseCommitAndTree(owner, repo, token string) (string, string, error) {
 	}
 
 	commitURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/commits/%s", owner, repo, ref.Object.SHA)
-	resp, err = doGet(commitURL, token)
+	resp, err = doGet(ctx, commitURL, token)
 	if err != nil {
 		return "", "", err
 	}
@@ -194,21 +161,29 @@ func getBaseCommitAndTree(owner, repo, token string) (string, string, error) {
 	return commit.SHA, commit.Tree.SHA, nil
 }
 
-func createTree(owner, repo string, file File, blobSHA, baseTreeSHA, token string) (string, error) {
+func createTree(ctx context.Context, owner, repo string, files []File, blobSHAs []string, baseTreeSHA string, token TokenSource) (string, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees", owner, repo)
+	entries := make([]map[string]interface{}, len(files))
+	for i, file := range files {
+		entries[i] = map[string]interface{}{
+			"path": file.Path,
+			"mode": file.mode(),
+			"type": "blob",
+			"sha":  blobSHAs[i],
+		}
+		if file.Delete {
+			// A null SHA removes the path from the base tree
+			entries[i]["sha"] = nil
+		}
+	}
 	body := map[string]interface{}{
-		"base_tree": baseTreeSHA,
-		"tree": []map[string]string{
-			{
-				"path": file.Path,
-				"mode": "100644",
-				"type": "blob",
-				"sha":  blobSHA,
-			},
-		},
+		"tree": entries,
+	}
+	if baseTreeSHA != "" {
+		body["base_tree"] = baseTreeSHA
 	}
 	data, _ := json.Marshal(body)
-	resp, err := doPost(url, data, token)
+	resp, err := doPost(ctx, url, data, token)
 	if err != nil {
 		return "", err
 	}
@@ -219,15 +194,19 @@ func createTree(owner, repo string, file File, blobSHA, baseTreeSHA, token strin
 	return result.SHA, nil
 }
 
-func createCommit(owner, repo string, file File, treeSHA, parentSHA, token string) (string, error) {
+func createCommit(ctx context.Context, owner, repo, message, treeSHA, parentSHA string, token TokenSource) (string, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/commits", owner, repo)
+	parents := []string{}
+	if parentSHA != "" {
+		parents = append(parents, parentSHA)
+	}
 	body := map[string]interface{}{
-		"message": fmt.Sprintf("docs: add %s", file.Path),
+		"message": message,
 		"tree":    treeSHA,
-		"parents": []string{parentSHA},
+		"parents": parents,
 	}
 	data, _ := json.Marshal(body)
-	resp, err := doPost(url, data, token)
+	resp, err := doPost(ctx, url, data, token)
 	if err != nil {
 		return "", err
 	}
@@ -238,14 +217,25 @@ func createCommit(owner, repo string, file File, treeSHA, parentSHA, token strin
 	return result.SHA, nil
 }
 
-func updateRef(owner, repo, commitSHA, token string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/heads/main", owner, repo)
+func createRef(ctx context.Context, owner, repo, branch, sha string, token TokenSource) error {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs", owner, repo)
+	body := map[string]interface{}{
+		"ref": "refs/heads/" + branch,
+		"sha": sha,
+	}
+	data, _ := json.Marshal(body)
+	_, err := doPost(ctx, url, data, token)
+	return err
+}
+
+func updateRef(ctx context.Context, owner, repo, branch, commitSHA string, token TokenSource) error {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/heads/%s", owner, repo, branch)
 	body := map[string]interface{}{
 		"sha":   commitSHA,
-		"force": true,
+		"force": false,
 	}
 	data, _ := json.Marshal(body)
-	resp, err := doPatch(url, data, token)
+	resp, err := doPatch(ctx, url, data, token)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCommitToBranchRebuildsOnNewHead(t *testing.T) {
+	head := "aaa"
+	var parents []string
+	serveAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fields map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&fields)
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/me/app/git/refs/heads/main":
+			fmt.Fprintf(w, `{"object": {"sha": %q}}`, head)
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/repos/me/app/git/commits/"):
+			sha := strings.TrimPrefix(r.URL.Path, "/repos/me/app/git/commits/")
+			fmt.Fprintf(w, `{"sha": %q, "tree": {"sha": "tree-%s"}}`, sha, sha)
+		case r.Method == "POST" && r.URL.Path == "/repos/me/app/git/blobs":
+			fmt.Fprint(w, `{"sha": "blob"}`)
+		case r.Method == "POST" && r.URL.Path == "/repos/me/app/git/trees":
+			fmt.Fprintf(w, `{"sha": "tree-on-%s"}`, fields["base_tree"])
+		case r.Method == "POST" && r.URL.Path == "/repos/me/app/git/commits":
+			parent := fields["parents"].([]interface{})[0].(string)
+			parents = append(parents, parent)
+			fmt.Fprintf(w, `{"sha": "commit-on-%s"}`, parent)
+		case r.Method == "PATCH" && r.URL.Path == "/repos/me/app/git/refs/heads/main":
+			if fields["force"] != false {
+				t.Errorf("force = %v, want false", fields["force"])
+			}
+			// Someone pushed while the first commit was built
+			if head == "aaa" {
+				head = "bbb"
+				http.Error(w, `{"message":"Update is not a fast forward"}`, http.StatusUnprocessableEntity)
+				return
+			}
+			fmt.Fprintf(w, `{"object": {"sha": %q}}`, fields["sha"])
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	}))
+
+	sha, err := CommitToBranch(context.Background(), "me", "app", "main", "main", "Add file",
+		[]File{{Path: "a.txt", Content: []byte("a")}}, StaticToken("t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != "commit-on-bbb" {
+		t.Errorf("CommitToBranch = %s, want commit-on-bbb", sha)
+	}
+	if got := strings.Join(parents, ","); got != "aaa,bbb" {
+		t.Errorf("commit parents = %s, want aaa,bbb", got)
+	}
+}
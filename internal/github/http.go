@@ -2,6 +2,7 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,9 +11,34 @@ import (
 
 // HTTP utility functions for GitHub API requests
 
-func doGet(url, token string) ([]byte, error) {
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+// TokenSource supplies the token sent with each request. Sources that mint short-lived
+// tokens, such as AppTokenSource, refresh them as needed.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a fixed token such as a personal access token
+type StaticToken string
+
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// authorize sets the Authorization header of req from token
+func authorize(req *http.Request, token TokenSource) error {
+	t, err := token.Token(req.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t)
+	return nil
+}
+
+func doGet(ctx context.Context, url string, token TokenSource) ([]byte, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err := authorize(req, token); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 
 	resp, err := http.DefaultClient.Do(req)
@@ -27,15 +53,17 @@ func doGet(url, token string) ([]byte, error) {
 	}
 	
 	if resp.StatusCode >= 300 {
-		return body, fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, string(body))
+		return body, newAPIError(resp, body)
 	}
 	
 	return body, nil
 }
 
-func doPost(url string, data []byte, token string) ([]byte, error) {
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(data))
-	req.Header.Set("Authorization", "Bearer "+token)
+func doPost(ctx context.Context, url string, data []byte, token TokenSource) ([]byte, error) {
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(data))
+	if err := authorize(req, token); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("Content-Type", "application/json")
 
@@ -51,15 +79,17 @@ func doPost(url string, data []byte, token string) ([]byte, error) {
 	}
 	
 	if resp.StatusCode >= 300 {
-		return body, fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, string(body))
+		return body, newAPIError(resp, body)
 	}
 	
 	return body, nil
 }
 
-func doPatch(url string, data []byte, token string) ([]byte, error) {
-	req, _ := http.NewRequest("PATCH", url, bytes.NewBuffer(data))
-	req.Header.Set("Authorization", "Bearer "+token)
+func doPatch(ctx context.Context, url string, data []byte, token TokenSource) ([]byte, error) {
+	req, _ := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(data))
+	if err := authorize(req, token); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("Content-Type", "application/json")
 
@@ -75,12 +105,38 @@ func doPatch(url string, data []byte, token string) ([]byte, error) {
 	}
 	
 	if resp.StatusCode >= 300 {
-		return body, fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, string(body))
+		return body, newAPIError(resp, body)
 	}
 	
 	return body, nil
 }
 
+func doPut(ctx context.Context, url string, data []byte, token TokenSource) ([]byte, error) {
+	req, _ := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(data))
+	if err := authorize(req, token); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return body, newAPIError(resp, body)
+	}
+
+	return body, nil
+}
+
 // ParseErrorFromResponse attempts to extract an error message from a JSON response
 func ParseErrorFromResponse(resp []byte) error {
 	if len(resp) == 0 {
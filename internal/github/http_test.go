@@ -0,0 +1,34 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// serveAPI sends the requests the package makes to https://api.github.com to handler
+// for the rest of the test
+func serveAPI(t *testing.T, handler http.Handler) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	target, _ := url.Parse(server.URL)
+	saved := http.DefaultClient.Transport
+	http.DefaultClient.Transport = rewriteHost{host: target.Host, base: http.DefaultTransport}
+	t.Cleanup(func() {
+		http.DefaultClient.Transport = saved
+		server.Close()
+	})
+}
+
+// rewriteHost sends every request to host over plain HTTP
+type rewriteHost struct {
+	host string
+	base http.RoundTripper
+}
+
+func (r rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = "http", r.host
+	return r.base.RoundTrip(req)
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// issuesPerPage is the page size used when listing issues (GitHub's maximum)
+const issuesPerPage = 100
+
+// ListIssues returns every open and closed issue in the repository, following pagination.
+// Pull requests, which the issues endpoint also returns, are left out.
+func ListIssues(ctx context.Context, owner, repo string, token TokenSource) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues?state=all&per_page=%d&page=%d",
+			owner, repo, issuesPerPage, page)
+		resp, err := doGet(ctx, url, token)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []struct {
+			Issue
+			PullRequest *json.RawMessage `json:"pull_request,omitempty"`
+		}
+		if err := json.Unmarshal(resp, &batch); err != nil {
+			return nil, fmt.Errorf("failed to decode issues: %w", err)
+		}
+
+		for _, item := range batch {
+			if item.PullRequest == nil {
+				issues = append(issues, item.Issue)
+			}
+		}
+
+		if len(batch) < issuesPerPage {
+			return issues, nil
+		}
+	}
+}
+
+// CreateIssueComment adds a markdown comment to an existing issue
+func CreateIssueComment(ctx context.Context, owner, repo string, issueNumber int, token TokenSource, body string) error {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/comments", owner, repo, issueNumber)
+	data, _ := json.Marshal(map[string]string{"body": body})
+	_, err := doPost(ctx, url, data, token)
+	return err
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// License is an open source license as served by the GitHub licenses API. Body contains
+// placeholders such as [year] and [fullname] to be filled in.
+type License struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	SPDXID string `json:"spdx_id"`
+	Body   string `json:"body"`
+}
+
+// GetLicense fetches a license template by key, e.g. "mit" or "apache-2.0"
+func GetLicense(ctx context.Context, key string, token TokenSource) (*License, error) {
+	url := fmt.Sprintf("https://api.github.com/licenses/%s", key)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var license License
+	if err := json.Unmarshal(resp, &license); err != nil {
+		return nil, fmt.Errorf("failed to decode license: %w", err)
+	}
+	return &license, nil
+}
+
+// GetCodeOfConduct fetches a code of conduct template by key, e.g. "contributor_covenant"
+func GetCodeOfConduct(ctx context.Context, key string, token TokenSource) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/codes_of_conduct/%s", key)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return "", err
+	}
+
+	var conduct struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(resp, &conduct); err != nil {
+		return "", fmt.Errorf("failed to decode code of conduct: %w", err)
+	}
+	return conduct.Body, nil
+}
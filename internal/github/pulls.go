@@ -0,0 +1,263 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// checkRunsPerPage is the page size used when listing check runs
+const checkRunsPerPage = 100
+
+// PullRequest is the subset of the pull request resource the agent uses
+type PullRequest struct {
+	Number         int    `json:"number"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	State          string `json:"state"`
+	Draft          bool   `json:"draft"`
+	Merged         bool   `json:"merged"`
+	Mergeable      *bool  `json:"mergeable"`
+	MergeableState string `json:"mergeable_state"`
+	HTMLURL        string `json:"html_url"`
+	Head           struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// NewPullRequest describes a pull request to open
+type NewPullRequest struct {
+	Title string
+	Head  string
+	Base  string
+	Body  string
+	Draft bool
+	// Closes lists issues to link with "Closes #N" so they close when the PR merges
+	Closes []int
+}
+
+// PullRequestUpdate holds the fields to change on an existing pull request.
+// Nil fields are left untouched, so an empty body clears it.
+type PullRequestUpdate struct {
+	Title *string `json:"title,omitempty"`
+	Body  *string `json:"body,omitempty"`
+	Base  *string `json:"base,omitempty"`
+	State *string `json:"state,omitempty"` // "open" or "closed"
+}
+
+// Check is a single check run or commit status reported on a pull request's head commit
+type Check struct {
+	Name       string
+	Status     string // queued, in_progress or completed
+	Conclusion string // success, failure, neutral, ... once completed
+	URL        string
+}
+
+// PullRequestStatus summarises whether a pull request is ready to merge
+type PullRequestStatus struct {
+	PullRequest *PullRequest
+	// State is the combined result of all checks: success, pending or failure
+	State  string
+	Checks []Check
+}
+
+// CreatePullRequest opens a pull request merging pr.Head into pr.Base
+func CreatePullRequest(ctx context.Context, owner, repo string, token TokenSource, pr NewPullRequest) (*PullRequest, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls", owner, repo)
+	data, _ := json.Marshal(map[string]interface{}{
+		"title": pr.Title,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"body":  LinkIssues(pr.Body, pr.Closes),
+		"draft": pr.Draft,
+	})
+	resp, err := doPost(ctx, url, data, token)
+	if err != nil {
+		return nil, err
+	}
+	return decodePullRequest(resp)
+}
+
+// GetPullRequest fetches a pull request by number
+func GetPullRequest(ctx context.Context, owner, repo string, number int, token TokenSource) (*PullRequest, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%d", owner, repo, number)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return nil, err
+	}
+	return decodePullRequest(resp)
+}
+
+// UpdatePullRequest changes the title, body, base branch or state of a pull request
+func UpdatePullRequest(ctx context.Context, owner, repo string, number int, token TokenSource, update PullRequestUpdate) (*PullRequest, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%d", owner, repo, number)
+	data, _ := json.Marshal(update)
+	resp, err := doPatch(ctx, url, data, token)
+	if err != nil {
+		return nil, err
+	}
+	return decodePullRequest(resp)
+}
+
+// RequestReviewers asks users and/or teams (by slug) to review a pull request
+func RequestReviewers(ctx context.Context, owner, repo string, number int, token TokenSource, reviewers, teamReviewers []string) error {
+	if len(reviewers) == 0 && len(teamReviewers) == 0 {
+		return nil
+	}
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%d/requested_reviewers", owner, repo, number)
+	data, _ := json.Marshal(map[string][]string{
+		"reviewers":      nonNil(reviewers),
+		"team_reviewers": nonNil(teamReviewers),
+	})
+	_, err := doPost(ctx, url, data, token)
+	return err
+}
+
+// AddLabels adds labels to an issue or pull request, creating missing labels on the fly
+func AddLabels(ctx context.Context, owner, repo string, number int, token TokenSource, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/labels", owner, repo, number)
+	data, _ := json.Marshal(map[string][]string{"labels": labels})
+	_, err := doPost(ctx, url, data, token)
+	return err
+}
+
+// GetPullRequestStatus fetches a pull request together with the check runs and commit
+// statuses reported on its head commit
+func GetPullRequestStatus(ctx context.Context, owner, repo string, number int, token TokenSource) (*PullRequestStatus, error) {
+	pr, err := GetPullRequest(ctx, owner, repo, number, token)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := listCheckRuns(ctx, owner, repo, pr.Head.SHA, token)
+	if err != nil {
+		return nil, err
+	}
+
+	statusURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s/status", owner, repo, pr.Head.SHA)
+	resp, err := doGet(ctx, statusURL, token)
+	if err != nil {
+		return nil, err
+	}
+	var combined struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			State     string `json:"state"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := json.Unmarshal(resp, &combined); err != nil {
+		return nil, fmt.Errorf("failed to decode commit status: %w", err)
+	}
+
+	status := &PullRequestStatus{PullRequest: pr, Checks: checks}
+	for _, s := range combined.Statuses {
+		check := Check{Name: s.Context, Status: "completed", Conclusion: s.State, URL: s.TargetURL}
+		if s.State == "pending" {
+			check.Status, check.Conclusion = "in_progress", ""
+		}
+		status.Checks = append(status.Checks, check)
+	}
+	status.State = combinedState(status.Checks)
+	return status, nil
+}
+
+// listCheckRuns fetches every check run reported on a commit, page by page
+func listCheckRuns(ctx context.Context, owner, repo, sha string, token TokenSource) ([]Check, error) {
+	var checks []Check
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s/check-runs?per_page=%d&page=%d",
+			owner, repo, sha, checkRunsPerPage, page)
+		resp, err := doGet(ctx, url, token)
+		if err != nil {
+			return nil, err
+		}
+		var runs struct {
+			TotalCount int `json:"total_count"`
+			CheckRuns  []struct {
+				Name       string `json:"name"`
+				Status     string `json:"status"`
+				Conclusion string `json:"conclusion"`
+				HTMLURL    string `json:"html_url"`
+			} `json:"check_runs"`
+		}
+		if err := json.Unmarshal(resp, &runs); err != nil {
+			return nil, fmt.Errorf("failed to decode check runs: %w", err)
+		}
+
+		for _, run := range runs.CheckRuns {
+			checks = append(checks, Check{
+				Name:       run.Name,
+				Status:     run.Status,
+				Conclusion: run.Conclusion,
+				URL:        run.HTMLURL,
+			})
+		}
+		if len(runs.CheckRuns) < checkRunsPerPage || len(checks) >= runs.TotalCount {
+			return checks, nil
+		}
+	}
+}
+
+// LinkIssues appends a "Closes #N" line for each issue not already referenced in body
+// by a closing keyword
+func LinkIssues(body string, issues []int) string {
+	var refs []string
+	for _, n := range issues {
+		if !closesIssue(body, n) {
+			refs = append(refs, fmt.Sprintf("Closes #%d", n))
+		}
+	}
+	if len(refs) == 0 {
+		return body
+	}
+	if body == "" {
+		return strings.Join(refs, "\n")
+	}
+	return body + "\n\n" + strings.Join(refs, "\n")
+}
+
+// closesIssue reports whether body links issue n with one of GitHub's closing keywords,
+// such as "Fixes #12". The number must match whole, so #1 isn't found in #12.
+func closesIssue(body string, n int) bool {
+	re := regexp.MustCompile(fmt.Sprintf(`(?i)\b(close[sd]?|fix(e[sd])?|resolve[sd]?):?\s+#%d\b`, n))
+	return re.MatchString(body)
+}
+
+// combinedState reduces checks to success, pending or failure
+func combinedState(checks []Check) string {
+	state := "success"
+	for _, c := range checks {
+		switch {
+		case c.Status != "completed":
+			state = "pending"
+		case c.Conclusion != "success" && c.Conclusion != "neutral" && c.Conclusion != "skipped":
+			return "failure"
+		}
+	}
+	return state
+}
+
+func decodePullRequest(resp []byte) (*PullRequest, error) {
+	var pr PullRequest
+	if err := json.Unmarshal(resp, &pr); err != nil {
+		return nil, fmt.Errorf("failed to decode pull request: %w", err)
+	}
+	return &pr, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
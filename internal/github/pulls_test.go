@@ -0,0 +1,78 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestUpdatePullRequestSendsOnlySetFields(t *testing.T) {
+	var sent map[string]interface{}
+	serveAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/repos/me/app/pulls/7" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprintf(w, `{"number": 7, "title": "Old title", "body": %q, "state": "open"}`, sent["body"])
+	}))
+
+	// An empty body is sent so it clears the description; the title is left alone
+	body := ""
+	pr, err := UpdatePullRequest(context.Background(), "me", "app", 7, StaticToken("t"), PullRequestUpdate{Body: &body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent["body"] != "" {
+		t.Errorf("sent %v, want only an empty body", sent)
+	}
+	if pr.Number != 7 || pr.Body != "" {
+		t.Errorf("pull request = #%d %q, want #7 with no body", pr.Number, pr.Body)
+	}
+}
+
+func TestGetPullRequestStatusCombinesChecksAndStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		wantState string
+	}{
+		{"all passing", "success", "success"},
+		{"status pending", "pending", "pending"},
+		{"status failed", "failure", "failure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/repos/me/app/pulls/7":
+					fmt.Fprint(w, `{"number": 7, "head": {"ref": "feature", "sha": "abc"}}`)
+				case "/repos/me/app/commits/abc/check-runs":
+					fmt.Fprint(w, `{"total_count": 2, "check_runs": [
+						{"name": "build", "status": "completed", "conclusion": "success", "html_url": "https://ci/build"},
+						{"name": "lint", "status": "completed", "conclusion": "skipped"}]}`)
+				case "/repos/me/app/commits/abc/status":
+					fmt.Fprintf(w, `{"statuses": [{"context": "deploy", "state": %q, "target_url": "https://ci/deploy"}]}`, tt.status)
+				default:
+					http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+				}
+			}))
+
+			status, err := GetPullRequestStatus(context.Background(), "me", "app", 7, StaticToken("t"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.PullRequest.Head.SHA != "abc" {
+				t.Errorf("head = %s, want abc", status.PullRequest.Head.SHA)
+			}
+			if len(status.Checks) != 3 || status.Checks[2].Name != "deploy" {
+				t.Fatalf("checks = %+v, want build, lint and deploy", status.Checks)
+			}
+			if status.State != tt.wantState {
+				t.Errorf("state = %s, want %s", status.State, tt.wantState)
+			}
+		})
+	}
+}
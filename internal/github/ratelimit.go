@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+)
+
+// APIError is a GitHub API response with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is how long GitHub asked the client to wait, from the Retry-After or
+	// X-RateLimit-Reset headers; zero when it didn't say
+	RetryAfter time.Duration
+	// RateLimited is set for primary and secondary rate limit responses
+	RateLimited bool
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// isNotFound reports whether err is a GitHub 404 response
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// newAPIError builds the error for a response with an error status
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	if e.RetryAfter == 0 && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			e.RetryAfter = time.Until(time.Unix(reset, 0))
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		e.RateLimited = true
+	case http.StatusForbidden:
+		// Secondary rate limits come back as 403 with an explanatory message
+		e.RateLimited = e.RetryAfter > 0 || strings.Contains(strings.ToLower(e.Body), "rate limit")
+	}
+	return e
+}
+
+// Backoff pauses every request sharing it while GitHub is rate limiting, so concurrent
+// workers back off together instead of each hitting the limit in turn. It is safe for
+// concurrent use.
+type Backoff struct {
+	// MaxAttempts is how many times a rate-limited request is sent before giving up
+	MaxAttempts int
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// defaultBackoffDelay is waited after a rate limit response that doesn't say how long to wait
+const defaultBackoffDelay = time.Minute
+
+// Do runs op, waiting out any shared pause first and retrying it when GitHub rate
+// limits it. Other errors are returned straight away.
+func (b *Backoff) Do(ctx context.Context, op func() error) error {
+	attempts := b.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		err := op()
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.RateLimited || attempt == attempts {
+			return err
+		}
+
+		delay := apiErr.RetryAfter
+		if delay <= 0 {
+			delay = defaultBackoffDelay
+		}
+		logging.FromContext(ctx).WarnContext(ctx, "GitHub rate limit reached, pausing requests", "delay", delay, "attempt", attempt)
+		b.pause(delay)
+	}
+}
+
+// pause holds back every request until delay from now, unless a longer pause is set
+func (b *Backoff) pause(delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until := time.Now().Add(delay); until.After(b.until) {
+		b.until = until
+	}
+}
+
+// wait blocks until no pause is set; another worker may extend it in the meantime
+func (b *Backoff) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		delay := time.Until(b.until)
+		b.mu.Unlock()
+		if delay <= 0 {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RepoSettings are repository options managed through PATCH /repos/{owner}/{repo}.
+// Nil fields are not managed and keep whatever value the repository has.
+type RepoSettings struct {
+	AllowSquashMerge    *bool `json:"allow_squash_merge,omitempty" yaml:"allow_squash_merge"`
+	AllowMergeCommit    *bool `json:"allow_merge_commit,omitempty" yaml:"allow_merge_commit"`
+	AllowRebaseMerge    *bool `json:"allow_rebase_merge,omitempty" yaml:"allow_rebase_merge"`
+	AllowAutoMerge      *bool `json:"allow_auto_merge,omitempty" yaml:"allow_auto_merge"`
+	DeleteBranchOnMerge *bool `json:"delete_branch_on_merge,omitempty" yaml:"delete_branch_on_merge"`
+	HasIssues           *bool `json:"has_issues,omitempty" yaml:"has_issues"`
+	HasWiki             *bool `json:"has_wiki,omitempty" yaml:"has_wiki"`
+	HasProjects         *bool `json:"has_projects,omitempty" yaml:"has_projects"`
+}
+
+// BranchProtection is the desired protection of a single branch
+type BranchProtection struct {
+	Branch                  string   `yaml:"branch"`
+	RequiredReviews         int      `yaml:"required_reviews"`
+	DismissStaleReviews     bool     `yaml:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews bool     `yaml:"require_code_owner_reviews"`
+	RequiredStatusChecks    []string `yaml:"required_status_checks"`
+	// StrictStatusChecks requires branches to be up to date before merging
+	StrictStatusChecks   bool `yaml:"strict_status_checks"`
+	EnforceAdmins        bool `yaml:"enforce_admins"`
+	RequireLinearHistory bool `yaml:"require_linear_history"`
+	AllowForcePushes     bool `yaml:"allow_force_pushes"`
+	AllowDeletions       bool `yaml:"allow_deletions"`
+}
+
+// Settings is the declarative description of a repository's configuration
+type Settings struct {
+	Repository       RepoSettings       `yaml:"repository"`
+	BranchProtection []BranchProtection `yaml:"branch_protection"`
+}
+
+// SettingChange is one difference between the current and desired settings
+type SettingChange struct {
+	// Resource is "repository" or "branch <name>"
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Current  string `json:"current"`
+	Desired  string `json:"desired"`
+}
+
+// DiffSettings compares the repository's current configuration with the desired one.
+// An empty result means applying the settings would change nothing.
+func DiffSettings(ctx context.Context, owner, repo string, token TokenSource, desired Settings) ([]SettingChange, error) {
+	current, err := getRepoSettings(ctx, owner, repo, token)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffFields("repository", current.fields(), desired.Repository.fields())
+
+	for _, want := range desired.BranchProtection {
+		have, err := getBranchProtection(ctx, owner, repo, want.Branch, token)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffFields("branch "+want.Branch, have.fields(), want.fields())...)
+	}
+	return changes, nil
+}
+
+// ApplySettings brings the repository in line with the desired settings, only touching
+// what differs. It returns the changes made; running it twice makes no further changes.
+func ApplySettings(ctx context.Context, owner, repo string, token TokenSource, desired Settings) ([]SettingChange, error) {
+	changes, err := DiffSettings(ctx, owner, repo, token, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]bool{}
+	for _, c := range changes {
+		changed[c.Resource] = true
+	}
+
+	if changed["repository"] {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+		data, _ := json.Marshal(desired.Repository)
+		if _, err := doPatch(ctx, url, data, token); err != nil {
+			return nil, fmt.Errorf("failed to update repository settings: %w", err)
+		}
+	}
+
+	for _, p := range desired.BranchProtection {
+		if !changed["branch "+p.Branch] {
+			continue
+		}
+		if err := putBranchProtection(ctx, owner, repo, token, p); err != nil {
+			return nil, fmt.Errorf("failed to protect branch %s: %w", p.Branch, err)
+		}
+	}
+	return changes, nil
+}
+
+func getRepoSettings(ctx context.Context, owner, repo string, token TokenSource) (RepoSettings, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		return RepoSettings{}, err
+	}
+	var settings RepoSettings
+	if err := json.Unmarshal(resp, &settings); err != nil {
+		return RepoSettings{}, fmt.Errorf("failed to decode repository: %w", err)
+	}
+	return settings, nil
+}
+
+// getBranchProtection returns the branch's current protection; an unprotected branch
+// is reported as the zero BranchProtection
+func getBranchProtection(ctx context.Context, owner, repo, branch string, token TokenSource) (BranchProtection, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/branches/%s/protection", owner, repo, branch)
+	resp, err := doGet(ctx, url, token)
+	if err != nil {
+		if isNotFound(err) {
+			return BranchProtection{Branch: branch}, nil
+		}
+		return BranchProtection{}, err
+	}
+
+	type enabled struct {
+		Enabled bool `json:"enabled"`
+	}
+	var result struct {
+		Reviews *struct {
+			Count           int  `json:"required_approving_review_count"`
+			DismissStale    bool `json:"dismiss_stale_reviews"`
+			CodeOwnerReview bool `json:"require_code_owner_reviews"`
+		} `json:"required_pull_request_reviews"`
+		StatusChecks *struct {
+			Strict   bool     `json:"strict"`
+			Contexts []string `json:"contexts"`
+		} `json:"required_status_checks"`
+		EnforceAdmins    enabled `json:"enforce_admins"`
+		LinearHistory    enabled `json:"required_linear_history"`
+		AllowForcePushes enabled `json:"allow_force_pushes"`
+		AllowDeletions   enabled `json:"allow_deletions"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return BranchProtection{}, fmt.Errorf("failed to decode branch protection: %w", err)
+	}
+
+	p := BranchProtection{
+		Branch:               branch,
+		EnforceAdmins:        result.EnforceAdmins.Enabled,
+		RequireLinearHistory: result.LinearHistory.Enabled,
+		AllowForcePushes:     result.AllowForcePushes.Enabled,
+		AllowDeletions:       result.AllowDeletions.Enabled,
+	}
+	if result.Reviews != nil {
+		p.RequiredReviews = result.Reviews.Count
+		p.DismissStaleReviews = result.Reviews.DismissStale
+		p.RequireCodeOwnerReviews = result.Reviews.CodeOwnerReview
+	}
+	if result.StatusChecks != nil {
+		p.StrictStatusChecks = result.StatusChecks.Strict
+		p.RequiredStatusChecks = result.StatusChecks.Contexts
+	}
+	return p, nil
+}
+
+func putBranchProtection(ctx context.Context, owner, repo string, token TokenSource, p BranchProtection) error {
+	body := map[string]interface{}{
+		"enforce_admins":                p.EnforceAdmins,
+		"required_linear_history":       p.RequireLinearHistory,
+		"allow_force_pushes":            p.AllowForcePushes,
+		"allow_deletions":               p.AllowDeletions,
+		"restrictions":                  nil,
+		"required_status_checks":        nil,
+		"required_pull_request_reviews": nil,
+	}
+	if len(p.RequiredStatusChecks) > 0 || p.StrictStatusChecks {
+		body["required_status_checks"] = map[string]interface{}{
+			"strict":   p.StrictStatusChecks,
+			"contexts": nonNil(p.RequiredStatusChecks),
+		}
+	}
+	if p.RequiredReviews > 0 || p.DismissStaleReviews || p.RequireCodeOwnerReviews {
+		body["required_pull_request_reviews"] = map[string]interface{}{
+			"required_approving_review_count": p.RequiredReviews,
+			"dismiss_stale_reviews":           p.DismissStaleReviews,
+			"require_code_owner_reviews":      p.RequireCodeOwnerReviews,
+		}
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/branches/%s/protection", owner, repo, p.Branch)
+	data, _ := json.Marshal(body)
+	_, err := doPut(ctx, url, data, token)
+	return err
+}
+
+// fields flattens the managed (non-nil) settings into field name/value pairs
+func (s RepoSettings) fields() map[string]string {
+	all := map[string]*bool{
+		"allow_squash_merge":     s.AllowSquashMerge,
+		"allow_merge_commit":     s.AllowMergeCommit,
+		"allow_rebase_merge":     s.AllowRebaseMerge,
+		"allow_auto_merge":       s.AllowAutoMerge,
+		"delete_branch_on_merge": s.DeleteBranchOnMerge,
+		"has_issues":             s.HasIssues,
+		"has_wiki":               s.HasWiki,
+		"has_projects":           s.HasProjects,
+	}
+	out := map[string]string{}
+	for name, v := range all {
+		if v != nil {
+			out[name] = fmt.Sprint(*v)
+		}
+	}
+	return out
+}
+
+func (p BranchProtection) fields() map[string]string {
+	checks := append([]string(nil), p.RequiredStatusChecks...)
+	sort.Strings(checks)
+	return map[string]string{
+		"required_reviews":           fmt.Sprint(p.RequiredReviews),
+		"dismiss_stale_reviews":      fmt.Sprint(p.DismissStaleReviews),
+		"require_code_owner_reviews": fmt.Sprint(p.RequireCodeOwnerReviews),
+		"required_status_checks":     "[" + strings.Join(checks, ", ") + "]",
+		"strict_status_checks":       fmt.Sprint(p.StrictStatusChecks),
+		"enforce_admins":             fmt.Sprint(p.EnforceAdmins),
+		"require_linear_history":     fmt.Sprint(p.RequireLinearHistory),
+		"allow_force_pushes":         fmt.Sprint(p.AllowForcePushes),
+		"allow_deletions":            fmt.Sprint(p.AllowDeletions),
+	}
+}
+
+// diffFields lists the desired fields whose value differs from the current one
+func diffFields(resource string, current, desired map[string]string) []SettingChange {
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []SettingChange
+	for _, name := range names {
+		if current[name] != desired[name] {
+			changes = append(changes, SettingChange{
+				Resource: resource,
+				Field:    name,
+				Current:  current[name],
+				Desired:  desired[name],
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GenerateFromTemplate creates owner/name from a GitHub template repository given as
+// "template-owner/template-repo"
+func GenerateFromTemplate(ctx context.Context, template, owner, name string, private bool, token TokenSource) (*Repository, error) {
+	templateOwner, templateRepo, ok := strings.Cut(template, "/")
+	if !ok || templateOwner == "" || templateRepo == "" {
+		return nil, fmt.Errorf("template repository must be owner/name, got %q", template)
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/generate", templateOwner, templateRepo)
+	data, _ := json.Marshal(map[string]interface{}{
+		"owner":   owner,
+		"name":    name,
+		"private": private,
+	})
+	resp, err := doPost(ctx, url, data, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var repo Repository
+	if err := json.Unmarshal(resp, &repo); err != nil {
+		return nil, fmt.Errorf("failed to decode repository: %w", err)
+	}
+	return &repo, nil
+}
+
+// WaitForBranch polls until branch exists. Repositories generated from a template are
+// populated asynchronously, so their branches appear a few seconds after creation.
+func WaitForBranch(ctx context.Context, owner, repo, branch string, token TokenSource, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		_, _, err := getBaseCommitAndTree(ctx, owner, repo, branch, token)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("branch %s did not appear within %s: %w", branch, timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package logging builds the CLI's structured logger and redacts secrets from what it
+// logs, including traced HTTP requests and responses
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Log formats accepted by New
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New returns a logger writing to w in format. It logs warnings and errors, and debug
+// messages too when verbose is set. Attributes with secret-looking keys are redacted.
+func New(w io.Writer, format string, verbose bool) (*slog.Logger, error) {
+	level := slog.LevelWarn
+	if verbose {
+		level = slog.LevelDebug
+	}
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+
+	switch format {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q (want %s or %s)", format, FormatText, FormatJSON)
+}
+
+// contextKey carries the logger in a context
+type contextKey struct{}
+
+// WithLogger returns a copy of ctx carrying l. The API packages log through the logger
+// of the context they are called with.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default when it has none
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// Redacted replaces a secret value
+const Redacted = "[REDACTED]"
+
+// secretKeys are substrings of attribute, header and JSON field names whose values
+// are never logged
+var secretKeys = []string{"authorization", "token", "api_key", "apikey", "secret", "password", "passphrase", "private_key"}
+
+// IsSecretKey reports whether a value named key must be redacted. Token counts, such
+// as prompt_tokens, are not secret.
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, "tokens") {
+		return false
+	}
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Bearer and basic credentials, wherever they end up
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}`), "$1 " + Redacted},
+	// JSON fields with secret names, such as "token": "ghs_..." or "api_key": "sk-..."
+	{regexp.MustCompile(`(?i)("[^"]*(?:token|api_key|apikey|secret|password|passphrase|private_key)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`), `$1"` + Redacted + `"`},
+	// GitHub tokens and OpenAI keys wherever they appear
+	{regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|sk-[A-Za-z0-9_-]{20,})`), Redacted},
+	// PEM private keys
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), Redacted},
+}
+
+// Redact removes tokens, API keys and other credentials from s
+func Redact(s string) string {
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if IsSecretKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	switch v := a.Value.Any().(type) {
+	case string:
+		return slog.String(a.Key, Redact(v))
+	case error:
+		return slog.String(a.Key, Redact(v.Error()))
+	}
+	return a
+}
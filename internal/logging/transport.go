@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+)
+
+// Transport logs every request it sends at debug level, with the full request and
+// response when Bodies is set. Credentials are redacted from everything it logs.
+type Transport struct {
+	// Base sends the requests; nil means http.DefaultTransport
+	Base   http.RoundTripper
+	Logger *slog.Logger
+	// Bodies dumps headers and bodies as well as the request line and status
+	Bodies bool
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.Bodies {
+		if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+			t.Logger.DebugContext(ctx, "http request", "dump", redactDump(dump))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base().RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		t.Logger.DebugContext(ctx, "http request failed", "method", req.Method, "url", req.URL.String(), "elapsed", elapsed, "error", err)
+		return nil, err
+	}
+	t.Logger.DebugContext(ctx, "http request", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "elapsed", elapsed)
+
+	if t.Bodies {
+		// Reading a stream to dump it would hold back every event until the end
+		body := !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+		if dump, err := httputil.DumpResponse(resp, body); err == nil {
+			t.Logger.DebugContext(ctx, "http response", "dump", redactDump(dump))
+		}
+	}
+	return resp, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// redactDump removes credentials from a dumped request or response, blanking secret
+// headers before redacting the rest of the text
+func redactDump(dump []byte) string {
+	lines := strings.Split(string(dump), "\r\n")
+	for i, line := range lines {
+		if line == "" {
+			// The body starts after the first blank line
+			break
+		}
+		if name, _, ok := strings.Cut(line, ":"); ok && IsSecretKey(name) {
+			lines[i] = name + ": " + Redacted
+		}
+	}
+	return Redact(strings.Join(lines, "\r\n"))
+}
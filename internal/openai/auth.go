@@ -0,0 +1,31 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const modelsURL = "https://api.openai.com/v1/models"
+
+// CheckKey verifies that an API key is accepted by OpenAI
+func CheckKey(ctx context.Context, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", modelsURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OpenAI rejected the key (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
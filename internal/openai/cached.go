@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"context"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/cache"
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+)
+
+const cacheProvider = "openai"
+
+// responses serves repeated completions from disk when set
+var responses *cache.Cache
+
+// SetCache serves completions of requests seen before from c, and stores new ones in
+// it. A nil cache sends every request.
+func SetCache(c *cache.Cache) {
+	responses = c
+}
+
+// freshKey marks a context whose completions skip cached responses
+type freshKey struct{}
+
+// Fresh returns a context whose completions are always sent, for when the user asks for
+// a new answer. The new response replaces the cached one.
+func Fresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+// cached returns the cache key of a completion request and the stored response, if
+// there is one that parse accepts. The key is empty when there is no cache.
+func cached(ctx context.Context, model string, messages []ChatMessage, parse func(string) error) (string, string, bool) {
+	if responses == nil {
+		return "", "", false
+	}
+	// Streaming doesn't change the response, so both kinds of call share entries
+	key, err := cache.Key(cacheProvider, ChatRequest{Model: model, Messages: messages})
+	if err != nil {
+		return "", "", false
+	}
+	if fresh, _ := ctx.Value(freshKey{}).(bool); fresh {
+		return key, "", false
+	}
+	e, ok := responses.Get(key)
+	if !ok {
+		return key, "", false
+	}
+	if err := parse(e.Content); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "ignoring cached LLM response that doesn't parse", "key", key[:12], "error", err)
+		return key, "", false
+	}
+	if meter != nil {
+		meter.RecordCached(model)
+	}
+	logging.FromContext(ctx).DebugContext(ctx, "LLM response served from cache", "model", model, "key", key[:12])
+	return key, e.Content, true
+}
+
+// store saves a response under key. Failing to write the cache doesn't fail the call.
+func store(ctx context.Context, key, model string, messages []ChatMessage, content string) {
+	if responses == nil || key == "" {
+		return
+	}
+	summary := ""
+	if len(messages) > 0 {
+		summary = strings.Join(strings.Fields(messages[len(messages)-1].Content), " ")
+		if runes := []rune(summary); len(runes) > 80 {
+			summary = string(runes[:77]) + "..."
+		}
+	}
+	err := responses.Put(cache.Entry{
+		Key:      key,
+		Provider: cacheProvider,
+		Model:    model,
+		Summary:  summary,
+		Content:  content,
+	})
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "failed to cache LLM response", "error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+	"github.com/TheAlonso95/ai-dev-agent/internal/prompts"
+)
+
+const chatCompletionsURL = "https://api.openai.com/v1/chat/completions"
+
+// complete sends a chat completion request and returns the content of the first choice.
+// parse is called with the content before it is returned; a response is only cached
+// once parse accepts it, so a malformed reply is asked for again rather than replayed.
+func complete(ctx context.Context, model string, messages []ChatMessage, apiKey string, parse func(string) error) (string, error) {
+	key, content, ok := cached(ctx, model, messages, parse)
+	if ok {
+		return content, nil
+	}
+
+	if meter != nil {
+		if err := meter.Reserve(model, messages); err != nil {
+			return "", err
+		}
+	}
+
+	reqData := ChatRequest{
+		Model:    model,
+		Messages: messages,
+	}
+
+	jsonData, err := json.Marshal(reqData)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", chatCompletionsURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var result ChatResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+
+	if result.Error != nil {
+		return "", fmt.Errorf("OpenAI error: %s", result.Error.Message)
+	}
+
+	if result.Usage != nil {
+		if result.Model == "" {
+			result.Model = model
+		}
+		logging.FromContext(ctx).DebugContext(ctx, "LLM call", "model", result.Model,
+			"prompt_tokens", result.Usage.PromptTokens, "completion_tokens", result.Usage.CompletionTokens)
+		if meter != nil {
+			meter.Record(result.Model, *result.Usage)
+		}
+	}
+
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no choices returned by OpenAI")
+	}
+
+	content = result.Choices[0].Message.Content
+	if err := parse(content); err != nil {
+		return "", err
+	}
+	store(ctx, key, model, messages, content)
+	return content, nil
+}
+
+// promptMessages renders the system and user templates of a prompt into chat messages
+func promptMessages(name string, data map[string]any) ([]ChatMessage, error) {
+	system, err := prompts.Render(name+".system", data)
+	if err != nil {
+		return nil, err
+	}
+	user, err := prompts.Render(name+".user", data)
+	if err != nil {
+		return nil, err
+	}
+	return []ChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	}, nil
+}
+
+// anyContent accepts every response, for completions used as plain text
+func anyContent(string) error { return nil }
+
+// stripCodeFence removes a surrounding markdown code fence (```json ... ```) that
+// models sometimes add around JSON output
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	if i := strings.Index(content, "\n"); i >= 0 {
+		content = content[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Question is a clarifying question about a project idea. Topic is a short key such as
+// platform or auth, used to answer questions from a file.
+type Question struct {
+	Topic    string `json:"topic"`
+	Question string `json:"question"`
+}
+
+// AskClarifyingQuestions asks the model what it needs to know about an idea before
+// planning it
+func AskClarifyingQuestions(ctx context.Context, idea, techStack, apiKey string) ([]Question, error) {
+	messages, err := promptMessages("clarify", map[string]any{
+		"Idea":  idea,
+		"Stack": techStack,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []Question
+	parse := func(content string) error {
+		if err := json.Unmarshal([]byte(stripCodeFence(content)), &questions); err != nil {
+			return fmt.Errorf("failed to parse questions JSON: %w", err)
+		}
+		return nil
+	}
+	if _, err := complete(ctx, "o4-mini-2025-04-16", messages, apiKey, parse); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
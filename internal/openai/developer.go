@@ -0,0 +1,57 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ContextFile is an existing repository file shown to the model as context
+type ContextFile struct {
+	Path    string
+	Content string
+}
+
+// FileChange is the full new content of a file the model wants to create or modify,
+// or a file it wants removed
+type FileChange struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Delete  bool   `json:"delete,omitempty"`
+}
+
+// ChangeSet is the model's proposed implementation of an issue
+type ChangeSet struct {
+	Summary       string       `json:"summary"`
+	CommitMessage string       `json:"commit_message"`
+	Files         []FileChange `json:"files"`
+}
+
+// ProposeChanges asks the model to implement an issue given a selection of repository files
+func ProposeChanges(ctx context.Context, issueTitle, issueBody string, files []ContextFile, apiKey string) (*ChangeSet, error) {
+	messages, err := promptMessages("develop", map[string]any{
+		"IssueTitle": issueTitle,
+		"IssueBody":  issueBody,
+		"Files":      files,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var changes ChangeSet
+	parse := func(content string) error {
+		changes = ChangeSet{}
+		if err := json.Unmarshal([]byte(stripCodeFence(content)), &changes); err != nil {
+			return fmt.Errorf("failed to parse change set JSON: %w", err)
+		}
+		if len(changes.Files) == 0 {
+			return fmt.Errorf("model proposed no file changes")
+		}
+		return nil
+	}
+	if _, err := complete(ctx, "o4-mini-2025-04-16", messages, apiKey, parse); err != nil {
+		return nil, err
+	}
+
+	return &changes, nil
+}
@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// GeneratedFile is one entry of the file manifest the model proposes for a new repository
+type GeneratedFile struct {
+	Path    string `json:"path"`
+	Purpose string `json:"purpose"`
+	Content string `json:"content"`
+}
+
+// GenerateFiles asks the model for the initial file structure of a project as a manifest
+// of paths, their purpose and their contents. The README is generated separately by
+// GenerateReadme and is not part of the manifest.
+func GenerateFiles(ctx context.Context, projectName, idea, techStack, apiKey string) ([]GeneratedFile, error) {
+	messages, err := promptMessages("files", map[string]any{
+		"ProjectName": projectName,
+		"Idea":        idea,
+		"Stack":       techStack,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		Files []GeneratedFile `json:"files"`
+	}
+	parse := func(content string) error {
+		if err := json.Unmarshal([]byte(stripCodeFence(content)), &manifest); err != nil {
+			return fmt.Errorf("failed to parse file manifest JSON: %w", err)
+		}
+		if len(manifest.Files) == 0 {
+			return fmt.Errorf("model proposed no files")
+		}
+		return nil
+	}
+	if _, err := complete(ctx, "o4-mini-2025-04-16", messages, apiKey, parse); err != nil {
+		return nil, err
+	}
+
+	return manifest.Files, nil
+}
@@ -1,12 +1,11 @@
 package openai
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 
+	"github.com/TheAlonso95/ai-dev-agent/internal/prompts"
 	. "github.com/TheAlonso95/ai-dev-agent/internal/tasks"
 )
 
@@ -16,8 +15,10 @@ type ChatMessage struct {
 }
 
 type ChatRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
+	Model         string         `json:"model"`
+	Messages      []ChatMessage  `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
 }
 
 type ChatResponse struct {
@@ -26,62 +27,59 @@ type ChatResponse struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Model string `json:"model"`
+	Usage *Usage `json:"usage,omitempty"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
-func AskForTasks(prompt, apiKey string) ([]Task, error) {
-	systemPrompt := "You are an expert software project planner.\n" +
-		"Given a project idea and tech stack, generate a list of development tasks formatted as JSON.\n" +
-		"Each task must include:\n" +
-		"- title: a short task summary\n" +
-		"- body: a detailed description using markdown\n" +
-		"- acceptance_criteria: a list of conditions that must be true for the task to be considered complete\n" +
-		"- labels: array of relevant labels (e.g., setup, backend, frontend, auth, db)\n\n" +
-		"Generate 5–10 high-quality tasks that follow best practices. Keep tasks atomic and suitable for GitHub Issues." +
-		"Return ONLY a JSON array of tasks using this format:\n" +
-		"[{\"title\": \"Task\", \"body\": \"...\", \"acceptance_criteria\": [...], \"labels\": [\"...\"]}]"
-
-	reqData := ChatRequest{
-		Model: "o4-mini-2025-04-16",
-		Messages: []ChatMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: prompt},
-		},
-	}
-	jsonData, _ := json.Marshal(reqData)
-
-	req, _ := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
+func AskForTasks(ctx context.Context, idea, techStack, apiKey string) ([]Task, error) {
+	messages, err := PlanMessages(idea, techStack, "")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, _ := ioutil.ReadAll(resp.Body)
-
-	var result ChatResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
+	_, taskList, err := ContinuePlan(ctx, messages, apiKey, nil)
+	return taskList, err
+}
 
-	if result.Error != nil {
-		return nil, fmt.Errorf("OpenAI error: %s", result.Error.Message)
-	}
+// PlanMessages returns the opening messages of a planning conversation. brief is the
+// rendered project brief, empty when there is none.
+func PlanMessages(idea, techStack, brief string) ([]ChatMessage, error) {
+	return promptMessages("plan", map[string]any{
+		"Idea":  idea,
+		"Stack": techStack,
+		"Brief": brief,
+	})
+}
 
-	if len(result.Choices) == 0 {
-		return nil, fmt.Errorf("no choices returned by OpenAI")
+// PlanFeedback appends the user's feedback on the latest task list to a planning
+// conversation
+func PlanFeedback(history []ChatMessage, feedback string) ([]ChatMessage, error) {
+	content, err := prompts.Render("plan-feedback.user", map[string]any{"Feedback": feedback})
+	if err != nil {
+		return nil, err
 	}
+	return append(history, ChatMessage{Role: "user", Content: content}), nil
+}
 
+// ContinuePlan sends a planning conversation and returns it with the model's reply
+// appended, along with the tasks in that reply. The reply is streamed to onDelta, which
+// may be nil, as it arrives. The history is left untouched on error so the turn can be
+// retried.
+func ContinuePlan(ctx context.Context, history []ChatMessage, apiKey string, onDelta func(string)) ([]ChatMessage, []Task, error) {
 	// Parse the JSON from the returned content
 	var taskList []Task
-	if err := json.Unmarshal([]byte(result.Choices[0].Message.Content), &taskList); err != nil {
-		return nil, fmt.Errorf("failed to parse task JSON: %w", err)
+	parse := func(content string) error {
+		if err := json.Unmarshal([]byte(stripCodeFence(content)), &taskList); err != nil {
+			return fmt.Errorf("failed to parse task JSON: %w", err)
+		}
+		return nil
+	}
+	content, err := streamComplete(ctx, "o4-mini-2025-04-16", history, apiKey, onDelta, parse)
+	if err != nil {
+		return history, nil, err
 	}
 
-	return taskList, nil
+	return append(history, ChatMessage{Role: "assistant", Content: content}), taskList, nil
 }
@@ -1,86 +1,18 @@
 package openai
 
-import (
-	"bytes"
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-)
-
-func GenerateReadme(projectName, idea, techStack, apiKey string) (string, error) {
-	ctx := context.Background()
-
-	systemPrompt := "You are an expert open source project maintainer.\n" +
-		"Generate a high-quality README.md for a GitHub repository given a project name, idea, and tech stack.\n" +
-		"The README should include: project title, description, features, tech stack, setup instructions, and contributing guidelines."
-
-	userPrompt := fmt.Sprintf(
-		"Project name: %s\nIdea: %s\nTech stack: %s",
-		projectName, idea, techStack,
-	)
-
-	type ChatRequest struct {
-		Model    string        `json:"model"`
-		Messages []ChatMessage `json:"messages"`
-	}
-
-	type ChatResponse struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error,omitempty"`
-	}
-
-	reqData := ChatRequest{
-		Model: "gpt-3.5-turbo",
-		Messages: []ChatMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userPrompt},
-		},
-	}
-
-	jsonData, err := json.Marshal(reqData)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+import "context"
+
+// GenerateReadme writes the README of a new project, streaming it to onDelta, which may
+// be nil, as it is generated
+func GenerateReadme(ctx context.Context, projectName, idea, techStack, apiKey string, onDelta func(string)) (string, error) {
+	messages, err := promptMessages("readme", map[string]any{
+		"ProjectName": projectName,
+		"Idea":        idea,
+		"Stack":       techStack,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var result ChatResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	if result.Error != nil {
-		return "", fmt.Errorf("OpenAI error: %s", result.Error.Message)
-	}
-
-	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("no choices returned by OpenAI")
+		return "", err
 	}
 
-	return result.Choices[0].Message.Content, nil
+	return streamComplete(ctx, "gpt-3.5-turbo", messages, apiKey, onDelta, anyContent)
 }
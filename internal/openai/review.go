@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+// SplitTask asks the model to break a task that is too large into smaller, independently
+// shippable tasks. project describes the idea and stack the task belongs to.
+func SplitTask(ctx context.Context, task Task, project, apiKey string) ([]Task, error) {
+	messages, err := taskMessages("split", project, task, "")
+	if err != nil {
+		return nil, err
+	}
+	var split []Task
+	parse := func(reply string) error {
+		if err := json.Unmarshal([]byte(stripCodeFence(reply)), &split); err != nil {
+			return fmt.Errorf("failed to parse task JSON: %w", err)
+		}
+		if len(split) == 0 {
+			return fmt.Errorf("model returned no tasks")
+		}
+		return nil
+	}
+	if _, err := complete(ctx, "o4-mini-2025-04-16", messages, apiKey, parse); err != nil {
+		return nil, err
+	}
+	return split, nil
+}
+
+// ReviseTask asks the model to rewrite a single task according to the user's feedback
+func ReviseTask(ctx context.Context, task Task, feedback, project, apiKey string) (Task, error) {
+	messages, err := taskMessages("revise", project, task, feedback)
+	if err != nil {
+		return Task{}, err
+	}
+	var revised Task
+	parse := func(reply string) error {
+		revised = Task{}
+		if err := json.Unmarshal([]byte(stripCodeFence(reply)), &revised); err != nil {
+			return fmt.Errorf("failed to parse task JSON: %w", err)
+		}
+		if revised.Title == "" {
+			return fmt.Errorf("model returned a task without a title")
+		}
+		return nil
+	}
+	if _, err := complete(ctx, "o4-mini-2025-04-16", messages, apiKey, parse); err != nil {
+		return Task{}, err
+	}
+	return revised, nil
+}
+
+// taskMessages renders a prompt asking the model to transform a single task
+func taskMessages(prompt, project string, task Task, feedback string) ([]ChatMessage, error) {
+	taskJSON, err := json.MarshalIndent(task, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return promptMessages(prompt, map[string]any{
+		"Project":  project,
+		"Task":     string(taskJSON),
+		"Feedback": feedback,
+	})
+}
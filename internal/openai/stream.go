@@ -0,0 +1,159 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/logging"
+)
+
+// StreamOptions asks a streamed completion to report its usage in the last chunk
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatChunk is one server-sent event of a streamed completion
+type chatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Model string `json:"model"`
+	Usage *Usage `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// streamComplete sends a streamed chat completion request, passing each piece of content
+// to onDelta as it arrives, and returns the assembled content. Cancelling ctx stops the
+// stream and returns ctx's error. A cached response is passed to onDelta in one piece.
+// As with complete, the response is only cached once parse accepts it.
+func streamComplete(ctx context.Context, model string, messages []ChatMessage, apiKey string, onDelta func(string), parse func(string) error) (string, error) {
+	key, cachedContent, ok := cached(ctx, model, messages, parse)
+	if ok {
+		if onDelta != nil {
+			onDelta(cachedContent)
+		}
+		return cachedContent, nil
+	}
+
+	if meter != nil {
+		if err := meter.Reserve(model, messages); err != nil {
+			return "", err
+		}
+	}
+
+	reqData := ChatRequest{
+		Model:         model,
+		Messages:      messages,
+		Stream:        true,
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	}
+
+	jsonData, err := json.Marshal(reqData)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", chatCompletionsURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Errors are returned as a plain JSON body rather than a stream
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var result ChatResponse
+		if err := json.Unmarshal(body, &result); err == nil && result.Error != nil {
+			return "", fmt.Errorf("OpenAI error: %s", result.Error.Message)
+		}
+		return "", fmt.Errorf("OpenAI returned %s", resp.Status)
+	}
+
+	var content strings.Builder
+	var usage *Usage
+	done := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for !done && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to parse OpenAI stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("OpenAI error: %s", chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read OpenAI stream: %w", err)
+	}
+
+	if usage != nil {
+		logging.FromContext(ctx).DebugContext(ctx, "LLM call", "model", model, "stream", true,
+			"prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+		if meter != nil {
+			meter.Record(model, *usage)
+		}
+	}
+
+	if !done {
+		return "", fmt.Errorf("OpenAI stream ended before the response was complete")
+	}
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no content returned by OpenAI")
+	}
+	if err := parse(content.String()); err != nil {
+		return "", err
+	}
+	store(ctx, key, model, messages, content.String())
+	return content.String(), nil
+}
@@ -0,0 +1,216 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Usage is the token count OpenAI reports for a completion
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Prompt     float64 `yaml:"prompt"`
+	Completion float64 `yaml:"completion"`
+}
+
+// DefaultPrices are list prices of the models the CLI uses, in USD per million tokens.
+// Dated model names such as o4-mini-2025-04-16 match by prefix.
+var DefaultPrices = map[string]Price{
+	"gpt-3.5-turbo": {Prompt: 0.50, Completion: 1.50},
+	"gpt-4o":        {Prompt: 2.50, Completion: 10.00},
+	"gpt-4o-mini":   {Prompt: 0.15, Completion: 0.60},
+	"gpt-4.1":       {Prompt: 2.00, Completion: 8.00},
+	"gpt-4.1-mini":  {Prompt: 0.40, Completion: 1.60},
+	"o4-mini":       {Prompt: 1.10, Completion: 4.40},
+}
+
+// ErrBudgetExceeded is returned instead of making a call that would go over the budget
+var ErrBudgetExceeded = errors.New("LLM cost budget exceeded")
+
+// assumedCompletionTokens estimates the completion of a call before any has been made
+const assumedCompletionTokens = 2000
+
+// Call is the recorded usage of one completion
+type Call struct {
+	Model string
+	Usage Usage
+	// Cost is the estimated cost in USD; zero when the model has no price
+	Cost   float64
+	Priced bool
+	// Cached calls were served from the response cache at no cost
+	Cached bool
+}
+
+// Meter records the token usage and estimated cost of every completion and enforces an
+// optional budget. It is safe for concurrent use.
+type Meter struct {
+	// Prices are looked up by model name, falling back to the longest matching prefix
+	Prices map[string]Price
+	// Budget is the most the run may cost in USD; zero means no limit
+	Budget float64
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// meter receives the usage of every completion when set
+var meter *Meter
+
+// SetMeter records the usage of every following completion in m
+func SetMeter(m *Meter) {
+	meter = m
+}
+
+// NewMeter returns a meter using DefaultPrices with prices layered over them
+func NewMeter(prices map[string]Price, budget float64) *Meter {
+	all := map[string]Price{}
+	for model, p := range DefaultPrices {
+		all[model] = p
+	}
+	for model, p := range prices {
+		all[model] = p
+	}
+	return &Meter{Prices: all, Budget: budget}
+}
+
+// price finds the price of a model by exact name or longest prefix
+func (m *Meter) price(model string) (Price, bool) {
+	if p, ok := m.Prices[model]; ok {
+		return p, true
+	}
+	best := ""
+	for name := range m.Prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return m.Prices[best], true
+}
+
+// Reserve checks that a call to model with messages fits in what is left of the budget.
+// The prompt is estimated at four characters per token and the completion at the
+// average of earlier calls.
+func (m *Meter) Reserve(model string, messages []ChatMessage) error {
+	if m.Budget <= 0 {
+		return nil
+	}
+	p, ok := m.price(model)
+	if !ok {
+		return nil
+	}
+
+	promptTokens := 0
+	for _, msg := range messages {
+		promptTokens += len(msg.Content)/4 + 4
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spent, completed, made := 0.0, 0, 0
+	for _, c := range m.calls {
+		if c.Cached {
+			continue
+		}
+		spent += c.Cost
+		completed += c.Usage.CompletionTokens
+		made++
+	}
+	completionTokens := assumedCompletionTokens
+	if made > 0 {
+		completionTokens = completed / made
+	}
+
+	estimate := p.cost(Usage{PromptTokens: promptTokens, CompletionTokens: completionTokens})
+	if spent+estimate > m.Budget {
+		return fmt.Errorf("%w: spent $%.4f of $%.4f, the next call is estimated at $%.4f", ErrBudgetExceeded, spent, m.Budget, estimate)
+	}
+	return nil
+}
+
+// Record adds the usage of a completion
+func (m *Meter) Record(model string, usage Usage) {
+	p, ok := m.price(model)
+	call := Call{Model: model, Usage: usage, Priced: ok}
+	if ok {
+		call.Cost = p.cost(usage)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+// RecordCached adds a completion served from the response cache
+func (m *Meter) RecordCached(model string) {
+	_, ok := m.price(model)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Model: model, Priced: ok, Cached: true})
+}
+
+// Calls returns the recorded calls in order
+func (m *Meter) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Summary is the usage of all calls to one model
+type Summary struct {
+	Model string
+	Calls int
+	// Cached counts the calls served from the response cache
+	Cached int
+	Usage  Usage
+	Cost   float64
+	Priced bool
+}
+
+// Summarize totals the recorded calls per model, sorted by model name, and overall
+func (m *Meter) Summarize() ([]Summary, Summary) {
+	byModel := map[string]*Summary{}
+	total := Summary{Model: "total", Priced: true}
+	for _, c := range m.Calls() {
+		s, ok := byModel[c.Model]
+		if !ok {
+			s = &Summary{Model: c.Model, Priced: c.Priced}
+			byModel[c.Model] = s
+		}
+		for _, t := range []*Summary{s, &total} {
+			t.Calls++
+			if c.Cached {
+				t.Cached++
+			}
+			t.Usage.PromptTokens += c.Usage.PromptTokens
+			t.Usage.CompletionTokens += c.Usage.CompletionTokens
+			t.Usage.TotalTokens += c.Usage.TotalTokens
+			t.Cost += c.Cost
+		}
+		if !c.Priced {
+			total.Priced = false
+		}
+	}
+
+	summaries := make([]Summary, 0, len(byModel))
+	for _, s := range byModel {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Model < summaries[j].Model })
+	return summaries, total
+}
+
+func (p Price) cost(u Usage) float64 {
+	return (float64(u.PromptTokens)*p.Prompt + float64(u.CompletionTokens)*p.Completion) / 1e6
+}
@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// FillVariables asks the model to answer template questions for a project. questions maps
+// variable names to the question describing them; the result maps names to values.
+func FillVariables(ctx context.Context, idea, techStack string, questions map[string]string, apiKey string) (map[string]string, error) {
+	messages, err := promptMessages("variables", map[string]any{
+		"Idea":      idea,
+		"Stack":     techStack,
+		"Questions": questions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]string
+	parse := func(content string) error {
+		values = nil
+		if err := json.Unmarshal([]byte(stripCodeFence(content)), &values); err != nil {
+			return fmt.Errorf("failed to parse variables JSON: %w", err)
+		}
+		for name := range questions {
+			if _, ok := values[name]; !ok {
+				return fmt.Errorf("model did not answer variable %s", name)
+			}
+		}
+		return nil
+	}
+	if _, err := complete(ctx, "o4-mini-2025-04-16", messages, apiKey, parse); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Brief is what was learned about a project before planning it: the answers to the
+// planner's clarifying questions
+type Brief struct {
+	Answers []Answer `json:"answers"`
+}
+
+// Answer is one answered clarifying question
+type Answer struct {
+	Topic    string `json:"topic"`
+	Question string `json:"question,omitempty"`
+	Answer   string `json:"answer"`
+}
+
+// String renders the brief for the planning prompt
+func (b *Brief) String() string {
+	if b == nil || len(b.Answers) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString("Project brief:\n")
+	for _, a := range b.Answers {
+		if a.Question == "" {
+			fmt.Fprintf(&s, "- %s: %s\n", a.Topic, a.Answer)
+			continue
+		}
+		fmt.Fprintf(&s, "- Q: %s\n  A: %s\n", a.Question, a.Answer)
+	}
+	return s.String()
+}
+
+// LoadAnswers reads answers to clarifying questions from a YAML file mapping topics to
+// answers:
+//
+//	platform: Web, desktop browsers first
+//	auth: No accounts, single user
+//	persistence: SQLite file next to the binary
+func LoadAnswers(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	answers := map[string]string{}
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&answers); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return answers, nil
+}
@@ -0,0 +1,151 @@
+// Package plan persists planning sessions so they can be resumed and later applied
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/config"
+	"github.com/TheAlonso95/ai-dev-agent/internal/openai"
+	"github.com/TheAlonso95/ai-dev-agent/internal/tasks"
+)
+
+// Session is a planning conversation and the task list it has arrived at
+type Session struct {
+	ID string `json:"id"`
+	// Name is the repository the plan is for
+	Name      string               `json:"name"`
+	Idea      string               `json:"idea"`
+	Stack     string               `json:"stack"`
+	Brief     *Brief               `json:"brief,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+	Messages  []openai.ChatMessage `json:"messages"`
+	// Tasks is the latest revision of the plan
+	Tasks []tasks.Task `json:"tasks"`
+	// Revisions counts the task lists the model has produced
+	Revisions int `json:"revisions"`
+}
+
+// validID matches session IDs, which name files and are typed on the command line
+var validID = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// New starts a session for a project; it is not saved until Save is called
+func New(name, idea, stack string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:        slug(name) + "-" + now.Format("20060102-150405"),
+		Name:      name,
+		Idea:      idea,
+		Stack:     stack,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// slug lowercases name and turns every run of characters other than letters and digits
+// into a single dash
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	if s := strings.Trim(b.String(), "-"); s != "" {
+		return s
+	}
+	return "plan"
+}
+
+// Dir returns the directory sessions are saved in
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// Save writes the session to Dir as <id>.json
+func (s *Session) Save() error {
+	if !validID.MatchString(s.ID) {
+		return fmt.Errorf("invalid session ID %q: only lowercase letters, digits and dashes are allowed", s.ID)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	s.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, s.ID+".json"), data, 0o600)
+}
+
+// Load reads a saved session by ID, or from a path to its file
+func Load(idOrPath string) (*Session, error) {
+	path := idOrPath
+	if !strings.ContainsRune(idOrPath, filepath.Separator) && !strings.HasSuffix(idOrPath, ".json") {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, idOrPath+".json")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no planning session %q (see 'plan list')", idOrPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// List returns the saved sessions, most recently updated first
+func List() ([]*Session, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	for _, p := range paths {
+		s, err := Load(p)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
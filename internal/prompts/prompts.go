@@ -0,0 +1,161 @@
+// Package prompts holds the LLM prompts as versioned text/template files. Built-in
+// prompts are embedded; a file with the same name in the override directory replaces one.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var builtinFS embed.FS
+
+// templateSuffix is the extension of prompt files, built-in and overrides alike
+const templateSuffix = ".tmpl"
+
+// overrideDir is searched for prompt overrides before the built-in templates
+var overrideDir string
+
+// header matches the leading template comment holding a prompt's metadata:
+//
+//	{{- /* version: 2
+//	description: ... */ -}}
+var header = regexp.MustCompile(`^\{\{-?\s*/\*([\s\S]*?)\*/\s*-?\}\}`)
+
+// Prompt describes a prompt template
+type Prompt struct {
+	Name        string
+	Description string
+	// Version is the version of the template in use
+	Version int
+	// BuiltinVersion is the version of the embedded template. An override with a lower
+	// version was written against an older prompt and may miss improvements.
+	BuiltinVersion int
+	// Override is the path of the override file in use, empty for the built-in template
+	Override string
+}
+
+// SetOverrideDir sets the directory searched for prompt overrides
+func SetOverrideDir(dir string) {
+	overrideDir = dir
+}
+
+// OverrideDir returns the directory searched for prompt overrides
+func OverrideDir() string {
+	return overrideDir
+}
+
+// Render executes the named prompt with data
+func Render(name string, data map[string]any) (string, error) {
+	source, _, err := load(name)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// List describes every built-in prompt, sorted by name
+func List() ([]Prompt, error) {
+	entries, err := fs.ReadDir(builtinFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	var list []Prompt
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), templateSuffix)
+		p, err := Describe(name)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Describe returns the metadata of a prompt
+func Describe(name string) (Prompt, error) {
+	builtin, err := fs.ReadFile(builtinFS, path.Join("templates", name+templateSuffix))
+	if err != nil {
+		return Prompt{}, fmt.Errorf("unknown prompt %q", name)
+	}
+	builtinMeta := parseHeader(string(builtin))
+
+	source, override, err := load(name)
+	if err != nil {
+		return Prompt{}, err
+	}
+	meta := parseHeader(source)
+	version, _ := strconv.Atoi(meta["version"])
+	builtinVersion, _ := strconv.Atoi(builtinMeta["version"])
+	description := meta["description"]
+	if description == "" {
+		description = builtinMeta["description"]
+	}
+	return Prompt{
+		Name:           name,
+		Description:    description,
+		Version:        version,
+		BuiltinVersion: builtinVersion,
+		Override:       override,
+	}, nil
+}
+
+// Builtin returns the source of the embedded template, as a starting point for an override
+func Builtin(name string) (string, error) {
+	data, err := fs.ReadFile(builtinFS, path.Join("templates", name+templateSuffix))
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt %q", name)
+	}
+	return string(data), nil
+}
+
+// load returns the source of a prompt and the override path it came from, if any
+func load(name string) (string, string, error) {
+	if overrideDir != "" {
+		p := filepath.Join(overrideDir, name+templateSuffix)
+		data, err := os.ReadFile(p)
+		if err == nil {
+			return string(data), p, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+	}
+	source, err := Builtin(name)
+	return source, "", err
+}
+
+// parseHeader reads "key: value" lines from a template's leading comment
+func parseHeader(source string) map[string]string {
+	meta := map[string]string{}
+	m := header.FindStringSubmatch(source)
+	if m == nil {
+		return meta
+	}
+	for _, line := range strings.Split(m[1], "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			meta[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return meta
+}
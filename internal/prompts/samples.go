@@ -0,0 +1,50 @@
+package prompts
+
+// sampleProject is shared by the samples of prompts about a new project
+var sampleProject = map[string]any{
+	"ProjectName": "pomodoro-timer",
+	"Idea":        "Pomodoro timer web app",
+	"Stack":       "Go, SQLite, React",
+}
+
+const sampleTask = `{
+  "title": "Add user accounts",
+  "body": "Let users sign up and log in so their sessions are saved.",
+  "acceptance_criteria": ["Users can sign up", "Users can log in"],
+  "labels": ["auth", "backend"]
+}`
+
+// samples are example inputs for rendering each prompt with 'prompts render'
+var samples = map[string]map[string]any{
+	"plan.user": {
+		"Idea":  "Pomodoro timer web app",
+		"Stack": "Go, SQLite, React",
+		"Brief": "Project brief:\n- Q: Do users need accounts?\n  A: No, single user",
+	},
+	"plan-feedback.user": {"Feedback": "Merge the auth tasks and add observability"},
+	"readme.user":        sampleProject,
+	"files.user":         sampleProject,
+	"clarify.user":       sampleProject,
+	"develop.user": {
+		"IssueTitle": "Add a /healthz endpoint",
+		"IssueBody":  "Return 200 OK so the load balancer can check the service.",
+		"Files": []map[string]string{
+			{"Path": "main.go", "Content": "package main\n\nfunc main() {}"},
+		},
+	},
+	"split.user":  {"Project": "Pomodoro timer web app (stack: Go, SQLite, React)", "Task": sampleTask},
+	"revise.user": {"Project": "Pomodoro timer web app (stack: Go, SQLite, React)", "Task": sampleTask, "Feedback": "Use magic links instead of passwords"},
+	"variables.user": {
+		"Idea":      "Pomodoro timer web app",
+		"Stack":     "Go, SQLite, React",
+		"Questions": map[string]string{"Port": "Which port should the server listen on?"},
+	},
+}
+
+// Sample returns example inputs for a prompt; system prompts take none
+func Sample(name string) map[string]any {
+	if data, ok := samples[name]; ok {
+		return data
+	}
+	return map[string]any{}
+}
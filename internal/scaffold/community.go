@@ -0,0 +1,78 @@
+package scaffold
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+//go:embed all:community
+var communityFS embed.FS
+
+// CommunityOptions configures the community health bundle
+type CommunityOptions struct {
+	// Family selects the build instructions in CONTRIBUTING.md (go, node, python or empty)
+	Family string
+	// LicenseText is the license template as served by the GitHub licenses API
+	LicenseText    string
+	CopyrightOwner string
+	Year           int
+	// CodeOfConduct is the code of conduct template as served by the GitHub API
+	CodeOfConduct string
+	// Contact is an email address for security reports and conduct issues; optional
+	Contact string
+}
+
+// Community renders LICENSE, CODE_OF_CONDUCT.md, CONTRIBUTING.md, SECURITY.md, issue
+// forms and a pull request template
+func Community(vars Vars, opts CommunityOptions) ([]github.File, error) {
+	root, err := fs.Sub(communityFS, "community")
+	if err != nil {
+		return nil, err
+	}
+
+	data := vars.Data()
+	data["Family"] = opts.Family
+	data["Contact"] = opts.Contact
+	files, err := renderFS(root, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.LicenseText != "" {
+		files = append(files, github.File{
+			Path:    "LICENSE",
+			Content: []byte(FillLicense(opts.LicenseText, opts.Year, opts.CopyrightOwner)),
+		})
+	}
+	if opts.CodeOfConduct != "" {
+		contact := opts.Contact
+		if contact == "" {
+			contact = fmt.Sprintf("https://github.com/%s/%s/security/advisories/new", vars.Owner, vars.ProjectName)
+		}
+		conduct := strings.NewReplacer(
+			"[INSERT CONTACT METHOD]", contact,
+			"[INSERT EMAIL ADDRESS]", contact,
+		).Replace(opts.CodeOfConduct)
+		files = append(files, github.File{Path: "CODE_OF_CONDUCT.md", Content: []byte(conduct)})
+	}
+	return files, nil
+}
+
+// FillLicense replaces the year and copyright holder placeholders used by the license
+// templates GitHub serves
+func FillLicense(text string, year int, owner string) string {
+	y := strconv.Itoa(year)
+	return strings.NewReplacer(
+		"[year]", y,
+		"[yyyy]", y,
+		"<year>", y,
+		"[fullname]", owner,
+		"[name of copyright owner]", owner,
+		"<name of author>", owner,
+	).Replace(text)
+}
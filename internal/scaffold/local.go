@@ -0,0 +1,108 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+// ManifestName is the file describing a local template directory. It is optional and
+// never copied into the generated project.
+const ManifestName = "template.json"
+
+// Variable sources: where a template variable's value comes from
+const (
+	SourceIdea   = "idea"   // the project idea passed to init
+	SourceStack  = "stack"  // the --stack value
+	SourceLLM    = "llm"    // answered by the model from the idea and stack
+	SourcePrompt = "prompt" // asked on the terminal (the default)
+)
+
+// Variable is a custom value a local template needs, beyond the built-in Vars
+type Variable struct {
+	Name string `json:"name"`
+	// Prompt is the question asked on the terminal, or to the model for SourceLLM
+	Prompt string `json:"prompt"`
+	// Default may reference the built-in Vars, e.g. "{{.ProjectName}}-service"
+	Default string `json:"default"`
+	Source  string `json:"source"`
+}
+
+// LocalTemplate is a template directory on disk, typically an organisation's golden path
+type LocalTemplate struct {
+	Dir         string
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Variables   []Variable `json:"variables"`
+}
+
+// LoadDir reads a local template directory and its manifest, if any
+func LoadDir(dir string) (*LocalTemplate, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	t := &LocalTemplate{Name: filepath.Base(dir)}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, t); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ManifestName, err)
+		}
+	}
+	t.Dir = dir
+
+	for i, v := range t.Variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("%s: variable %d has no name", ManifestName, i+1)
+		}
+		switch v.Source {
+		case "":
+			t.Variables[i].Source = SourcePrompt
+		case SourceIdea, SourceStack, SourceLLM, SourcePrompt:
+		default:
+			return nil, fmt.Errorf("%s: variable %s has unknown source %q", ManifestName, v.Name, v.Source)
+		}
+	}
+	return t, nil
+}
+
+// ExpandDefault renders a variable's default value against the built-in Vars
+func (v Variable) ExpandDefault(vars Vars) (string, error) {
+	tmpl, err := template.New(v.Name).Option("missingkey=error").Parse(v.Default)
+	if err != nil {
+		return "", fmt.Errorf("invalid default for %s: %w", v.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars.Data()); err != nil {
+		return "", fmt.Errorf("invalid default for %s: %w", v.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// Render produces the files of the template. values holds the resolved custom variables;
+// they are available to *.tmpl files next to the built-in Vars.
+func (t *LocalTemplate) Render(vars Vars, values map[string]string) ([]github.File, error) {
+	data := vars.Data()
+	for name, value := range values {
+		data[name] = value
+	}
+
+	return renderFS(os.DirFS(t.Dir), data, func(p string) bool {
+		return p == ManifestName || p == ".git"
+	})
+}
@@ -0,0 +1,74 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+// Limits applied to generated file manifests
+const (
+	MaxManifestFiles    = 50
+	MaxManifestFileSize = 100 << 10
+	MaxManifestSize     = 1 << 20
+)
+
+// forbiddenNames are files that must never be generated, matched on the base name
+var forbiddenNames = map[string]bool{
+	".env": true, ".npmrc": true, ".pypirc": true, ".netrc": true,
+	"id_rsa": true, "id_ed25519": true, "credentials.json": true,
+}
+
+// forbiddenExts are extensions of key and certificate material
+var forbiddenExts = map[string]bool{".pem": true, ".key": true, ".p12": true, ".pfx": true}
+
+// forbiddenDirs may not be written to by generated manifests. Workflows run with
+// repository secrets, so they only come from reviewed templates.
+var forbiddenDirs = []string{".git/", ".github/workflows/"}
+
+// ValidateManifest checks generated files for unsafe paths, forbidden files and size
+// limits, returning every problem found
+func ValidateManifest(files []github.File) error {
+	var errs []error
+	if len(files) > MaxManifestFiles {
+		errs = append(errs, fmt.Errorf("%d files exceeds the limit of %d", len(files), MaxManifestFiles))
+	}
+
+	seen := map[string]bool{}
+	total := 0
+	for _, f := range files {
+		if err := f.Check(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		clean := path.Clean(f.Path)
+		if seen[clean] {
+			errs = append(errs, fmt.Errorf("%s: listed more than once", f.Path))
+		}
+		seen[clean] = true
+
+		base := path.Base(clean)
+		if forbiddenNames[base] || strings.HasPrefix(base, ".env.") || forbiddenExts[path.Ext(base)] {
+			errs = append(errs, fmt.Errorf("%s: file type is not allowed", f.Path))
+		}
+		for _, dir := range forbiddenDirs {
+			if strings.HasPrefix(clean+"/", dir) {
+				errs = append(errs, fmt.Errorf("%s: generating files in %s is not allowed", f.Path, dir))
+			}
+		}
+
+		if len(f.Content) > MaxManifestFileSize {
+			errs = append(errs, fmt.Errorf("%s: %d bytes exceeds the per-file limit of %d", f.Path, len(f.Content), MaxManifestFileSize))
+		}
+		total += len(f.Content)
+	}
+
+	if total > MaxManifestSize {
+		errs = append(errs, fmt.Errorf("%d bytes in total exceeds the limit of %d", total, MaxManifestSize))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,213 @@
+// Package scaffold renders the initial file structure of new projects
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+//go:embed all:templates
+var builtinFS embed.FS
+
+// templateSuffix marks files rendered with text/template; the suffix is dropped from
+// the output path. Every other file is copied verbatim.
+const templateSuffix = ".tmpl"
+
+// funcs make free-form values such as the idea safe to place in source code
+var funcs = template.FuncMap{
+	// comment joins the value onto one line, for a line comment
+	"comment": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+	// quote renders the value as a double-quoted string literal that is valid in
+	// JavaScript, TypeScript, Python, TOML and JSON
+	"quote": func(s string) (string, error) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(s); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	},
+	// docstring renders the value on one line for the inside of a Python """ docstring
+	"docstring": func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	},
+	// jsxText renders the value on one line as JSX text, escaping braces and markup
+	"jsxText": func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "{", "&#123;", "}", "&#125;").Replace(s)
+	},
+}
+
+// Template is a built-in project template
+type Template struct {
+	Name        string
+	Description string
+}
+
+// Vars are the values available to templates
+type Vars struct {
+	ProjectName string
+	Owner       string
+	// Module is the Go module path, github.com/<owner>/<project>
+	Module string
+	Idea   string
+	Stack  string
+}
+
+// Builtin lists the templates shipped with the CLI
+var Builtin = []Template{
+	{Name: "go-cli", Description: "Go command-line tool"},
+	{Name: "go-web", Description: "Go HTTP web service with Dockerfile"},
+	{Name: "nextjs", Description: "Next.js app with TypeScript"},
+	{Name: "python-fastapi", Description: "Python FastAPI service with pytest"},
+}
+
+// Data returns the variables as a map, the form templates are executed with
+func (v Vars) Data() map[string]string {
+	return map[string]string{
+		"ProjectName": v.ProjectName,
+		"Owner":       v.Owner,
+		"Module":      v.Module,
+		"Idea":        v.Idea,
+		"Stack":       v.Stack,
+	}
+}
+
+// NewVars fills template variables for a project
+func NewVars(projectName, owner, idea, stack string) Vars {
+	return Vars{
+		ProjectName: projectName,
+		Owner:       owner,
+		Module:      fmt.Sprintf("github.com/%s/%s", owner, projectName),
+		Idea:        idea,
+		Stack:       stack,
+	}
+}
+
+// Lookup returns the built-in template with the given name
+func Lookup(name string) (Template, error) {
+	for _, t := range Builtin {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Template{}, fmt.Errorf("unknown template %q", name)
+}
+
+// MatchStack picks the built-in template that best fits a free-form tech stack such as
+// "Go, SQLite, React". It reports false when no template fits.
+func MatchStack(stack string) (Template, bool) {
+	has := stackMatcher(stack)
+
+	var name string
+	switch {
+	case has("next", "nextjs"):
+		name = "nextjs"
+	case has("fastapi", "python"):
+		name = "python-fastapi"
+	case has("go", "golang") && has("cli", "cobra", "command", "terminal"):
+		name = "go-cli"
+	case has("go", "golang"):
+		name = "go-web"
+	default:
+		return Template{}, false
+	}
+
+	t, err := Lookup(name)
+	return t, err == nil
+}
+
+// stackMatcher splits a free-form stack into lowercase words and returns a function
+// reporting whether any of the candidates is among them
+func stackMatcher(stack string) func(candidates ...string) bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(stack), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return func(candidates ...string) bool {
+		for _, c := range candidates {
+			if words[c] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Render produces the files of a built-in template
+func Render(t Template, vars Vars) ([]github.File, error) {
+	root, err := fs.Sub(builtinFS, path.Join("templates", t.Name))
+	if err != nil {
+		return nil, err
+	}
+	return renderFS(root, vars.Data(), nil)
+}
+
+// renderFS renders every file below root, executing *.tmpl files with data.
+// Paths for which skip returns true are left out.
+func renderFS(root fs.FS, data map[string]string, skip func(path string) bool) ([]github.File, error) {
+	var files []github.File
+	err := fs.WalkDir(root, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip != nil && skip(p) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		content, err := fs.ReadFile(root, p)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(p, templateSuffix) {
+			tmpl, err := template.New(p).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+			if err != nil {
+				return fmt.Errorf("failed to parse template %s: %w", p, err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return fmt.Errorf("failed to render template %s: %w", p, err)
+			}
+			p, content = strings.TrimSuffix(p, templateSuffix), buf.Bytes()
+		}
+
+		file := github.File{Path: p, Content: content}
+		// Embedded files carry no permission bits, so shell scripts are
+		// always made executable; files on disk keep their own bit
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if path.Ext(p) == ".sh" || info.Mode().Perm()&0o111 != 0 {
+			file.Mode = github.ModeExecutable
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
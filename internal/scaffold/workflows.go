@@ -0,0 +1,142 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/TheAlonso95/ai-dev-agent/internal/github"
+)
+
+// Workflow templates use [[ ]] delimiters so GitHub's own ${{ }} expressions pass through
+//
+//go:embed workflows
+var workflowFS embed.FS
+
+// DefaultActionsAllowlist allows exactly the tagged actions the built-in workflows use;
+// any other action must be pinned to a commit SHA or allowlisted explicitly
+var DefaultActionsAllowlist = []string{
+	"actions/checkout@v4",
+	"actions/setup-go@v5",
+	"actions/setup-node@v4",
+	"actions/setup-python@v5",
+}
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// WorkflowFamily picks the workflow set (go, node or python) that fits a tech stack.
+// It reports false when no workflow set fits.
+func WorkflowFamily(stack string) (string, bool) {
+	has := stackMatcher(stack)
+	switch {
+	case has("go", "golang"):
+		return "go", true
+	case has("python", "fastapi", "django", "flask"):
+		return "python", true
+	case has("node", "nodejs", "next", "nextjs", "react", "vue", "svelte", "typescript", "javascript"):
+		return "node", true
+	}
+	return "", false
+}
+
+// Workflows renders the CI and release workflows of a family into .github/workflows/.
+// CI runs on pushes and pull requests to branch; releases run on v* tags.
+func Workflows(family string, vars Vars, branch string) ([]github.File, error) {
+	entries, err := fs.ReadDir(workflowFS, path.Join("workflows", family))
+	if err != nil {
+		return nil, fmt.Errorf("unknown workflow family %q", family)
+	}
+
+	data := vars.Data()
+	data["Branch"] = branch
+
+	var files []github.File
+	for _, entry := range entries {
+		name := path.Join("workflows", family, entry.Name())
+		content, err := fs.ReadFile(workflowFS, name)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Delims("[[", "]]").Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse workflow %s: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render workflow %s: %w", name, err)
+		}
+		files = append(files, github.File{Path: ".github/workflows/" + entry.Name(), Content: buf.Bytes()})
+	}
+	return files, nil
+}
+
+// ValidateWorkflow checks that a workflow file is valid YAML with triggers and jobs, and
+// that every action it uses is either pinned to a full commit SHA or matches allowlist.
+// Allowlist entries are path.Match patterns against the action name ("actions/*") or
+// against name@ref ("actions/checkout@v4").
+func ValidateWorkflow(name string, content []byte, allowlist []string) error {
+	var workflow struct {
+		On   interface{} `yaml:"on"`
+		Jobs map[string]struct {
+			Uses  string `yaml:"uses"`
+			Steps []struct {
+				Uses string `yaml:"uses"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return fmt.Errorf("%s: invalid YAML: %w", name, err)
+	}
+	if workflow.On == nil {
+		return fmt.Errorf("%s: no triggers (on:) defined", name)
+	}
+	if len(workflow.Jobs) == 0 {
+		return fmt.Errorf("%s: no jobs defined", name)
+	}
+
+	var errs []error
+	for jobName, job := range workflow.Jobs {
+		uses := []string{job.Uses}
+		for _, step := range job.Steps {
+			uses = append(uses, step.Uses)
+		}
+		for _, u := range uses {
+			if u != "" && !actionAllowed(u, allowlist) {
+				errs = append(errs, fmt.Errorf("%s: job %s uses %s, which is neither pinned to a commit SHA nor allowlisted", name, jobName, u))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func actionAllowed(uses string, allowlist []string) bool {
+	// Local actions and reusable workflows live in the repository itself
+	if strings.HasPrefix(uses, "./") {
+		return true
+	}
+	if strings.HasPrefix(uses, "docker://") {
+		return strings.Contains(uses, "@sha256:")
+	}
+
+	action, ref, _ := strings.Cut(uses, "@")
+	if commitSHA.MatchString(ref) {
+		return true
+	}
+	for _, pattern := range allowlist {
+		if ok, _ := path.Match(pattern, action); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, uses); ok {
+			return true
+		}
+	}
+	return false
+}
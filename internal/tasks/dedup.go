@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DuplicateThreshold is the similarity score at or above which two tasks are
+// considered the same piece of work.
+const DuplicateThreshold = 0.6
+
+// stopWords are dropped before comparing text so that filler words don't
+// inflate the similarity of unrelated tasks.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true,
+	"for": true, "in": true, "on": true, "with": true, "by": true, "or": true,
+	"is": true, "be": true, "as": true, "at": true, "from": true, "into": true,
+}
+
+// NormalizeTitle lowercases a title, strips punctuation and collapses whitespace
+func NormalizeTitle(title string) string {
+	return strings.Join(strings.Fields(stripPunctuation(strings.ToLower(title))), " ")
+}
+
+// Similarity scores how alike two issues are, from 0 (unrelated) to 1 (same title).
+// Titles are weighted more heavily than bodies; bodies only count when both are present.
+func Similarity(title, body, otherTitle, otherBody string) float64 {
+	if NormalizeTitle(title) == NormalizeTitle(otherTitle) {
+		return 1
+	}
+
+	titleScore := jaccard(words(title), words(otherTitle))
+	if strings.TrimSpace(body) == "" || strings.TrimSpace(otherBody) == "" {
+		return titleScore
+	}
+	return 0.7*titleScore + 0.3*jaccard(words(body), words(otherBody))
+}
+
+func stripPunctuation(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		return ' '
+	}, s)
+}
+
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(NormalizeTitle(s)) {
+		if !stopWords[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,46 @@
+package tasks
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	if got, want := NormalizeTitle("  Add LOGIN-page!\tto  the app "), "add login page to the app"; got != want {
+		t.Errorf("NormalizeTitle = %q, want %q", got, want)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name                           string
+		title, body, otherTitle, other string
+		want                           float64
+	}{
+		{"titles equal once normalised", "Add login page!", "Build the form", "  add LOGIN   page", "Something else entirely", 1},
+		{"stop words are ignored", "Add user login page", "", "Add login page for users", "", 0.6},
+		{"title only when a body is missing", "Add login page", "Build the form", "Add signup page", "", 0.5},
+		{"bodies weigh 0.3", "Add login page", "Build the form", "Add signup page", "Build the form", 0.7*0.5 + 0.3*1},
+		{"different bodies lower the score", "Add login page", "Build the form", "Add signup page", "Write migrations", 0.7 * 0.5},
+		{"unrelated", "Add login page", "", "Set up CI", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.title, tt.body, tt.otherTitle, tt.other)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateThreshold(t *testing.T) {
+	// Sharing three of five title words is just enough to count as a duplicate
+	if s := Similarity("Add user login page", "", "Add login page for users", ""); s < DuplicateThreshold {
+		t.Errorf("Similarity = %v, want at least %v", s, DuplicateThreshold)
+	}
+	// Matching bodies don't make different titles a duplicate on their own
+	if s := Similarity("Add login page", "Build the form", "Set up CI", "Build the form"); s >= DuplicateThreshold {
+		t.Errorf("Similarity = %v, want below %v", s, DuplicateThreshold)
+	}
+}
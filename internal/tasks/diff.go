@@ -0,0 +1,93 @@
+package tasks
+
+import (
+	"slices"
+	"strings"
+)
+
+// Kinds of Change
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is one difference between two revisions of a task list
+type Change struct {
+	Kind string
+	// Old is unset for added tasks, New for removed ones
+	Old, New Task
+	// Fields lists what differs in a changed task: title, body, acceptance_criteria, labels
+	Fields []string
+}
+
+// Diff compares two revisions of a task list. Tasks are paired by title first, then by
+// similarity, so a reworded task shows up as changed rather than removed and added.
+func Diff(before, after []Task) []Change {
+	pair := make([]int, len(after))
+	used := make([]bool, len(before))
+	for j := range pair {
+		pair[j] = -1
+	}
+
+	// Identical titles first, then the most similar remaining task
+	for j, t := range after {
+		for i, old := range before {
+			if !used[i] && NormalizeTitle(old.Title) == NormalizeTitle(t.Title) {
+				pair[j], used[i] = i, true
+				break
+			}
+		}
+	}
+	for j, t := range after {
+		if pair[j] >= 0 {
+			continue
+		}
+		best, bestScore := -1, DuplicateThreshold
+		for i, old := range before {
+			if used[i] {
+				continue
+			}
+			if score := Similarity(t.Title, t.Body, old.Title, old.Body); score >= bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best >= 0 {
+			pair[j], used[best] = best, true
+		}
+	}
+
+	var changes []Change
+	for i, old := range before {
+		if !used[i] {
+			changes = append(changes, Change{Kind: Removed, Old: old})
+		}
+	}
+	for j, t := range after {
+		if pair[j] < 0 {
+			changes = append(changes, Change{Kind: Added, New: t})
+			continue
+		}
+		if fields := changedFields(before[pair[j]], t); len(fields) > 0 {
+			changes = append(changes, Change{Kind: Changed, Old: before[pair[j]], New: t, Fields: fields})
+		}
+	}
+	return changes
+}
+
+func changedFields(a, b Task) []string {
+	var fields []string
+	if a.Title != b.Title {
+		fields = append(fields, "title")
+	}
+	if strings.TrimSpace(a.Body) != strings.TrimSpace(b.Body) {
+		fields = append(fields, "body")
+	}
+	if !slices.Equal(a.AcceptanceCriteria, b.AcceptanceCriteria) {
+		fields = append(fields, "acceptance_criteria")
+	}
+	if !slices.Equal(a.Labels, b.Labels) {
+		fields = append(fields, "labels")
+	}
+	return fields
+}
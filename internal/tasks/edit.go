@@ -0,0 +1,85 @@
+package tasks
+
+import (
+	"errors"
+	"strings"
+)
+
+// criteriaHeading separates a task's body from its acceptance criteria in Format's output
+const criteriaHeading = "## Acceptance Criteria"
+
+// Format renders a task as markdown for editing by hand:
+//
+//	# Title
+//
+//	Labels: backend, db
+//
+//	Body...
+//
+//	## Acceptance Criteria
+//	- ...
+func Format(task Task) string {
+	var b strings.Builder
+	b.WriteString("# " + task.Title + "\n\n")
+	b.WriteString("Labels: " + strings.Join(task.Labels, ", ") + "\n\n")
+	if body := strings.TrimSpace(task.Body); body != "" {
+		b.WriteString(body + "\n\n")
+	}
+	b.WriteString(criteriaHeading + "\n")
+	for _, c := range task.AcceptanceCriteria {
+		b.WriteString("- " + c + "\n")
+	}
+	return b.String()
+}
+
+// Parse reads a task back from the markdown produced by Format. The first "# " line is
+// the title, an optional "Labels:" line follows, and everything after the last
+// acceptance criteria heading is read as a list.
+func Parse(text string) (Task, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var task Task
+	i := 0
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) == ""; i++ {
+	}
+	if i == len(lines) || !strings.HasPrefix(lines[i], "# ") {
+		return Task{}, errors.New("task must start with a '# Title' line")
+	}
+	task.Title = strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+	if task.Title == "" {
+		return Task{}, errors.New("task title is empty")
+	}
+	i++
+
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) == ""; i++ {
+	}
+	if i < len(lines) {
+		if labels, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), "Labels:"); ok {
+			for _, l := range strings.Split(labels, ",") {
+				if l = strings.TrimSpace(l); l != "" {
+					task.Labels = append(task.Labels, l)
+				}
+			}
+			i++
+		}
+	}
+
+	rest := lines[i:]
+	end := len(rest)
+	for j := len(rest) - 1; j >= 0; j-- {
+		if strings.EqualFold(strings.TrimSpace(rest[j]), criteriaHeading) {
+			end = j
+			break
+		}
+	}
+	task.Body = strings.TrimSpace(strings.Join(rest[:end], "\n"))
+	if end < len(rest) {
+		for _, line := range rest[end+1:] {
+			line = strings.TrimSpace(line)
+			if item, ok := strings.CutPrefix(line, "- "); ok && strings.TrimSpace(item) != "" {
+				task.AcceptanceCriteria = append(task.AcceptanceCriteria, strings.TrimSpace(item))
+			}
+		}
+	}
+	return task, nil
+}